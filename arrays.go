@@ -0,0 +1,508 @@
+package jsonlogic
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// Array sorting, slicing and aggregation operations
+	sortOp    = "sort"
+	sliceOp   = "slice"
+	firstOp   = "first"
+	lastOp    = "last"
+	countOp   = "count"
+	sumOp     = "sum"
+	avgOp     = "avg"
+	groupByOp = "group_by"
+	findOp    = "find"
+)
+
+// compareValues orders two values for sorting. The order is total, so
+// sorting gives the same result whatever the order of the input. Values
+// are ordered by type, null first, then booleans, numbers, strings, arrays
+// and objects. Strings that can be converted to numbers, such as "2", are
+// ordered with the numbers, after any number of the same value. Other
+// strings are compared lexically, arrays element by element, and objects by
+// their sorted keys, then the values of those keys.
+func compareValues(l, r interface{}) int {
+	lrank, rrank := sortRank(l), sortRank(r)
+	switch {
+	case lrank < rrank:
+		return -1
+	case lrank > rrank:
+		return 1
+	}
+
+	switch lrank {
+	case sortRankBool:
+		return compareInts(boolInt(l.(bool)), boolInt(r.(bool)))
+	case sortRankNumber:
+		if c, ok := compareNumbers(l, r); ok && c != 0 {
+			return c
+		}
+		if lnan, rnan := math.IsNaN(toNumber(l)), math.IsNaN(toNumber(r)); lnan != rnan {
+			if lnan {
+				return -1
+			}
+			return 1
+		}
+		// Equal numbers, with numbers before numeric strings.
+		lstr, lisstr := l.(string)
+		rstr, risstr := r.(string)
+		switch {
+		case lisstr && risstr:
+			return strings.Compare(lstr, rstr)
+		case lisstr:
+			return 1
+		case risstr:
+			return -1
+		}
+		return 0
+	case sortRankString:
+		return strings.Compare(l.(string), r.(string))
+	case sortRankArray:
+		larr, rarr := l.([]interface{}), r.([]interface{})
+		for i := 0; i < len(larr) && i < len(rarr); i++ {
+			if c := compareValues(larr[i], rarr[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(larr), len(rarr))
+	case sortRankObject:
+		lmap, rmap := l.(map[string]interface{}), r.(map[string]interface{})
+		lkeys, rkeys := sortedKeys(lmap), sortedKeys(rmap)
+		for i := 0; i < len(lkeys) && i < len(rkeys); i++ {
+			if c := strings.Compare(lkeys[i], rkeys[i]); c != 0 {
+				return c
+			}
+		}
+		if c := compareInts(len(lkeys), len(rkeys)); c != 0 {
+			return c
+		}
+		for _, k := range lkeys {
+			if c := compareValues(lmap[k], rmap[k]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case sortRankOther:
+		return strings.Compare(toString(l), toString(r))
+	}
+	return 0
+}
+
+const (
+	sortRankNull = iota
+	sortRankBool
+	sortRankNumber
+	sortRankString
+	sortRankArray
+	sortRankObject
+	sortRankOther
+)
+
+// sortRank returns the rank of the type of a value in the sort order.
+func sortRank(v interface{}) int {
+	switch v := v.(type) {
+	case nil:
+		return sortRankNull
+	case bool:
+		return sortRankBool
+	case string:
+		if strings.TrimFunc(v, isJSSpace) != "" && !math.IsNaN(toNumber(v)) {
+			return sortRankNumber
+		}
+		return sortRankString
+	case []interface{}:
+		return sortRankArray
+	case map[string]interface{}:
+		return sortRankObject
+	}
+	if isNumber(v) {
+		return sortRankNumber
+	}
+	return sortRankOther
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareInts(l, r int) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+// buildSortOp builds the sort operation. It takes an array, an optional key
+// expression which is evaluated against each element, and an optional
+// direction, "asc" (the default) or "desc". The direction may be given
+// in place of the key expression. Sorting is stable.
+func buildSortOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	keyArg := identityf
+	dirArg := func(context.Context, interface{}) interface{} {
+		return "asc"
+	}
+	switch {
+	case len(args) == 2:
+		if v, ok := constantArg(args[1]); ok && (v == "asc" || v == "desc") {
+			if dirArg, err = BuildArgFunc(args[1], ops); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if keyArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+	case len(args) >= 3:
+		if keyArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+		if dirArg, err = BuildArgFunc(args[2], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok {
+			return []interface{}{}
+		}
+		desc := dirArg(ctx, data) == "desc"
+
+		keys := make([]interface{}, len(lslice))
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			keys[i] = keyArg(sctx, subd)
+		}
+
+		idx := make([]int, len(lslice))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(i, j int) bool {
+			c := compareValues(keys[idx[i]], keys[idx[j]])
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		})
+
+		resp := make([]interface{}, len(lslice))
+		for i, j := range idx {
+			resp[i] = lslice[j]
+		}
+		return resp
+	}, nil
+}
+
+// sliceIndex resolves a JavaScript style slice index, where negative
+// values count back from the end of the array.
+func sliceIndex(i interface{}, length int) int {
+	f := math.Trunc(toNumber(i))
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f < 0:
+		return int(math.Max(float64(length)+f, 0))
+	default:
+		return int(math.Min(f, float64(length)))
+	}
+}
+
+// buildSliceOp builds the slice operation, which takes an array, a start
+// index and an optional end index, following Array.prototype.slice.
+func buildSliceOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	startArg := nullf
+	if len(args) >= 2 {
+		if startArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	var endArg ClauseFunc
+	if len(args) >= 3 {
+		if endArg, err = BuildArgFunc(args[2], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok {
+			return []interface{}{}
+		}
+
+		start := sliceIndex(startArg(ctx, data), len(lslice))
+		end := len(lslice)
+		if endArg != nil {
+			if endVal := endArg(ctx, data); endVal != nil {
+				end = sliceIndex(endVal, len(lslice))
+			}
+		}
+		if start >= end {
+			return []interface{}{}
+		}
+
+		resp := make([]interface{}, end-start)
+		copy(resp, lslice[start:end])
+		return resp
+	}, nil
+}
+
+func buildFirstOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok || len(lslice) == 0 {
+			return nil
+		}
+		return lslice[0]
+	}, nil
+}
+
+func buildLastOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok || len(lslice) == 0 {
+			return nil
+		}
+		return lslice[len(lslice)-1]
+	}, nil
+}
+
+// buildCountOp builds the count operation, which returns the length of an
+// array, or the number of elements for which the optional predicate is true.
+func buildCountOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return func(context.Context, interface{}) interface{} {
+			return 0.0
+		}, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	fArg := truef
+	if len(args) >= 2 {
+		if fArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok {
+			return 0.0
+		}
+
+		n := 0.0
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			if IsTrue(fArg(sctx, subd)) {
+				n++
+			}
+		}
+		return n
+	}, nil
+}
+
+// buildAggregateArgs builds the array and optional key expression arguments
+// of the sum and avg operations.
+func buildAggregateArgs(args Arguments, ops OpsSet) (ClauseFunc, ClauseFunc, error) {
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyArg := identityf
+	if len(args) >= 2 {
+		if keyArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, nil, err
+		}
+	}
+	return lArg, keyArg, nil
+}
+
+func buildSumOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return func(context.Context, interface{}) interface{} {
+			return 0.0
+		}, nil
+	}
+
+	lArg, keyArg, err := buildAggregateArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok {
+			return 0.0
+		}
+
+		resp := 0.0
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			item := toNumber(keyArg(sctx, subd))
+			if math.IsNaN(item) {
+				return item
+			}
+			resp += item
+		}
+		return resp
+	}, nil
+}
+
+func buildAvgOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+
+	lArg, keyArg, err := buildAggregateArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok || len(lslice) == 0 {
+			return nil
+		}
+
+		resp := 0.0
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			item := toNumber(keyArg(sctx, subd))
+			if math.IsNaN(item) {
+				return item
+			}
+			resp += item
+		}
+		return resp / float64(len(lslice))
+	}, nil
+}
+
+// buildGroupByOp builds the group_by operation, which evaluates the key
+// expression against each element, and returns an object mapping each
+// distinct key (as a string) to the array of elements that produced it.
+func buildGroupByOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return func(context.Context, interface{}) interface{} {
+			return map[string]interface{}{}
+		}, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	keyArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		resp := map[string]interface{}{}
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok {
+			return resp
+		}
+
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			key := toString(keyArg(sctx, subd))
+			group, _ := resp[key].([]interface{})
+			resp[key] = append(group, subd)
+		}
+		return resp
+	}, nil
+}
+
+// buildFindOp builds the find operation, which returns the first element
+// for which the predicate is true, or null.
+func buildFindOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	fArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice, ok := lArg(ctx, data).([]interface{})
+		if !ok {
+			return nil
+		}
+
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			if IsTrue(fArg(sctx, subd)) {
+				return subd
+			}
+		}
+		return nil
+	}, nil
+}
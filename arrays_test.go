@@ -0,0 +1,241 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayOps(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   interface{}
+		expect interface{}
+	}
+
+	var data interface{}
+	err := json.Unmarshal([]byte(`{
+		"orders": [
+			{"id": "a", "value": 10, "status": "paid"},
+			{"id": "b", "value": 30, "status": "refunded"},
+			{"id": "c", "value": 20, "status": "paid"},
+			{"id": "d", "value": 5, "status": "paid"},
+			{"id": "e", "value": 40, "status": "pending"},
+			{"id": "f", "value": 15, "status": "paid"}
+		],
+		"numbers": [3, 1, "2", 10],
+		"words": ["pear", "apple", "fig"]
+	}`), &data)
+	assert.NoError(t, err)
+
+	tests := []test{
+		{
+			name:   "sort-numbers",
+			rule:   `{"sort":{"var":"numbers"}}`,
+			data:   data,
+			expect: []interface{}{float64(1), "2", float64(3), float64(10)},
+		},
+		{
+			name:   "sort-mixed-numbers",
+			rule:   `{"sort":[[5,"10","9"]]}`,
+			expect: []interface{}{float64(5), "9", "10"},
+		},
+		{
+			name:   "sort-mixed-types",
+			rule:   `{"sort":{"var":"mixed"}}`,
+			data:   map[string]interface{}{"mixed": []interface{}{"b", []interface{}{1.0, 2.0}, 2.0, map[string]interface{}{"b": 1.0}, true, "2", nil, "a", []interface{}{1.0}, map[string]interface{}{"a": 2.0}, false, "10", 1.0}},
+			expect: []interface{}{nil, false, true, float64(1), float64(2), "2", "10", "a", "b", []interface{}{float64(1)}, []interface{}{float64(1), float64(2)}, map[string]interface{}{"a": float64(2)}, map[string]interface{}{"b": float64(1)}},
+		},
+		{
+			name:   "sort-words-desc",
+			rule:   `{"sort":[{"var":"words"},"desc"]}`,
+			data:   data,
+			expect: []interface{}{"pear", "fig", "apple"},
+		},
+		{
+			name:   "sort-key",
+			rule:   `{"map":[{"sort":[{"var":"orders"},{"var":"value"}]},{"var":"id"}]}`,
+			data:   data,
+			expect: []interface{}{"d", "a", "f", "c", "b", "e"},
+		},
+		{
+			name:   "sort-key-desc",
+			rule:   `{"map":[{"sort":[{"var":"orders"},{"var":"value"},"desc"]},{"var":"id"}]}`,
+			data:   data,
+			expect: []interface{}{"e", "b", "c", "f", "a", "d"},
+		},
+		{
+			name:   "sort-stable",
+			rule:   `{"map":[{"sort":[{"var":"orders"},{"var":"status"}]},{"var":"id"}]}`,
+			data:   data,
+			expect: []interface{}{"a", "c", "d", "f", "e", "b"},
+		},
+		{
+			name:   "sort-non-array",
+			rule:   `{"sort":[1]}`,
+			expect: []interface{}{},
+		},
+		{
+			name:   "slice",
+			rule:   `{"slice":[{"var":"words"},1]}`,
+			data:   data,
+			expect: []interface{}{"apple", "fig"},
+		},
+		{
+			name:   "slice-range",
+			rule:   `{"slice":[[1,2,3,4,5],1,3]}`,
+			expect: []interface{}{float64(2), float64(3)},
+		},
+		{
+			name:   "slice-negative",
+			rule:   `{"slice":[[1,2,3,4,5],-2]}`,
+			expect: []interface{}{float64(4), float64(5)},
+		},
+		{
+			name:   "slice-negative-end",
+			rule:   `{"slice":[[1,2,3,4,5],0,-3]}`,
+			expect: []interface{}{float64(1), float64(2)},
+		},
+		{
+			name:   "slice-out-of-range",
+			rule:   `{"slice":[[1,2,3],5,10]}`,
+			expect: []interface{}{},
+		},
+		{
+			name:   "first",
+			rule:   `{"first":{"var":"words"}}`,
+			data:   data,
+			expect: "pear",
+		},
+		{
+			name:   "first-empty",
+			rule:   `{"first":[[]]}`,
+			expect: nil,
+		},
+		{
+			name:   "last",
+			rule:   `{"last":{"var":"words"}}`,
+			data:   data,
+			expect: "fig",
+		},
+		{
+			name:   "count",
+			rule:   `{"count":{"var":"orders"}}`,
+			data:   data,
+			expect: float64(6),
+		},
+		{
+			name:   "count-predicate",
+			rule:   `{"count":[{"var":"orders"},{"==":[{"var":"status"},"paid"]}]}`,
+			data:   data,
+			expect: float64(4),
+		},
+		{
+			name:   "count-non-array",
+			rule:   `{"count":"abc"}`,
+			expect: float64(0),
+		},
+		{
+			name:   "sum",
+			rule:   `{"sum":{"var":"numbers"}}`,
+			data:   data,
+			expect: float64(16),
+		},
+		{
+			name:   "sum-key",
+			rule:   `{"sum":[{"var":"orders"},{"var":"value"}]}`,
+			data:   data,
+			expect: float64(120),
+		},
+		{
+			name:   "avg-last-5",
+			rule:   `{"avg":[{"slice":[{"var":"orders"},-5]},{"var":"value"}]}`,
+			data:   data,
+			expect: float64(22),
+		},
+		{
+			name:   "avg-empty",
+			rule:   `{"avg":[[]]}`,
+			expect: nil,
+		},
+		{
+			name: "group-by",
+			rule: `{"group_by":[{"var":"numbers"},{"<":[{"var":""},3]}]}`,
+			data: data,
+			expect: map[string]interface{}{
+				"true":  []interface{}{float64(1), "2"},
+				"false": []interface{}{float64(3), float64(10)},
+			},
+		},
+		{
+			name:   "find",
+			rule:   `{"find":[{"var":"orders"},{">":[{"var":"value"},25]}]}`,
+			data:   data,
+			expect: map[string]interface{}{"id": "b", "value": float64(30), "status": "refunded"},
+		},
+		{
+			name:   "find-none",
+			rule:   `{"find":[{"var":"orders"},{">":[{"var":"value"},100]}]}`,
+			data:   data,
+			expect: nil,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
+
+func TestCompareValuesTotalOrder(t *testing.T) {
+	values := []interface{}{
+		nil, false, true, math.NaN(), math.Inf(-1), -1.0, 0.0, 1, json.Number("2"), 2.0, "2", "2.0", " 3", "10",
+		math.Inf(1), "", " ", "a", "b", "abc", []interface{}{}, []interface{}{1.0}, []interface{}{"1"},
+		[]interface{}{1.0, nil}, map[string]interface{}{}, map[string]interface{}{"a": 1.0},
+		map[string]interface{}{"a": 2.0}, map[string]interface{}{"a": 1.0, "b": 1.0},
+	}
+
+	for _, a := range values {
+		assert.Equalf(t, 0, compareValues(a, a), "%#v with itself", a)
+		for _, b := range values {
+			ab, ba := compareValues(a, b), compareValues(b, a)
+			assert.Equalf(t, ab, -ba, "%#v and %#v are not antisymmetric", a, b)
+			for _, c := range values {
+				if ab <= 0 && compareValues(b, c) <= 0 {
+					assert.LessOrEqualf(t, compareValues(a, c), 0, "%#v, %#v and %#v are not transitive", a, b, c)
+				}
+			}
+		}
+	}
+
+	// Sorting gives the same result, whatever the order of the input.
+	ctx := context.Background()
+	cf, err := Compile(&Clause{Operator: Operator{Name: sortOp}, Arguments: Arguments{varArg("")}})
+	assert.NoError(t, err)
+	expect := cf(ctx, values)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		shuffled := append([]interface{}{}, values...)
+		r.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		got := cf(ctx, shuffled)
+		assert.Equal(t, formatDiffValue(expect), formatDiffValue(got))
+	}
+}
@@ -0,0 +1,58 @@
+package jsonlogic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+const (
+	// Rollout operations
+	bucketOp = "bucket"
+)
+
+// Bucket deterministically maps a key and salt to a number in the range
+// [0, 100). It is the implementation of the bucket operation, and is
+// defined so that it can be reproduced exactly in other languages:
+//
+//	h := SHA-256(UTF-8(salt + ":" + key))
+//	n := first 4 bytes of h, as a big-endian unsigned 32 bit integer
+//	bucket := n / 2^32 * 100
+//
+// For example, Bucket("user-1", "exp-42") is 66.49747400078923.
+func Bucket(key, salt string) float64 {
+	h := sha256.Sum256([]byte(salt + ":" + key))
+	n := binary.BigEndian.Uint32(h[:4])
+	return float64(n) / (1 << 32) * 100
+}
+
+// buildBucketOp builds the bucket operation, which takes a key and an
+// optional salt, e.g. {"bucket": [{"var":"user.id"}, "exp-42"]}. Both are
+// converted to strings. A null key results in null.
+func buildBucketOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+
+	keyArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	saltArg := func(context.Context, interface{}) interface{} {
+		return ""
+	}
+	if len(args) >= 2 {
+		if saltArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		key := keyArg(ctx, data)
+		if key == nil {
+			return nil
+		}
+		return Bucket(toString(key), toString(saltArg(ctx, data)))
+	}, nil
+}
@@ -0,0 +1,112 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket(t *testing.T) {
+	// These values were generated by an independent JavaScript
+	// implementation of the documented algorithm.
+	tests := []struct {
+		key, salt string
+		expect    float64
+	}{
+		{"user-1", "exp-42", 66.49747400078923},
+		{"42", "exp-42", 26.009959285147488},
+		{"user-2", "", 82.43018365465105},
+		{"ünïcode", "salt", 28.466376196593046},
+		{"user-1", "exp-43", 26.341807539574802},
+	}
+
+	for _, st := range tests {
+		t.Run(st.key+":"+st.salt, func(t *testing.T) {
+			assert.Equal(t, st.expect, Bucket(st.key, st.salt))
+		})
+	}
+}
+
+func TestBucketDistribution(t *testing.T) {
+	counts := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		b := Bucket(fmt.Sprintf("user-%d", i), "exp")
+		assert.True(t, b >= 0 && b < 100)
+		counts[int(b/10)]++
+	}
+	for i, c := range counts {
+		assert.InDeltaf(t, 1000, c, 100, "decile %d", i)
+	}
+}
+
+func TestBucketOp(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   interface{}
+		expect interface{}
+	}
+
+	data := map[string]interface{}{
+		"user": map[string]interface{}{
+			"id":  "user-1",
+			"num": float64(42),
+		},
+	}
+
+	tests := []test{
+		{
+			name:   "string-key",
+			rule:   `{"bucket":[{"var":"user.id"},"exp-42"]}`,
+			data:   data,
+			expect: 66.49747400078923,
+		},
+		{
+			name:   "numeric-key",
+			rule:   `{"bucket":[{"var":"user.num"},"exp-42"]}`,
+			data:   data,
+			expect: 26.009959285147488,
+		},
+		{
+			name:   "no-salt",
+			rule:   `{"bucket":"user-2"}`,
+			expect: 82.43018365465105,
+		},
+		{
+			name:   "missing-key",
+			rule:   `{"bucket":[{"var":"user.missing"},"exp-42"]}`,
+			data:   data,
+			expect: nil,
+		},
+		{
+			name:   "no-args",
+			rule:   `{"bucket":[]}`,
+			expect: nil,
+		},
+		{
+			name:   "rollout",
+			rule:   `{"<":[{"bucket":[{"var":"user.id"},"exp-42"]},25]}`,
+			data:   data,
+			expect: false,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
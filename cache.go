@@ -0,0 +1,100 @@
+package jsonlogic
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CacheStats holds the metrics of a CompileCache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Size is the number of compiled rules held.
+	Size int
+}
+
+type cacheEntry struct {
+	key string
+	cf  ClauseFunc
+}
+
+// CompileCache holds the most recently used compiled rules, so rules that
+// are compiled repeatedly are only compiled once. Rules are identified by
+// their Hash, so rules that are Equal, but written differently, share an
+// entry. A CompileCache is safe for concurrent use.
+type CompileCache struct {
+	ops  OpsSet
+	size int
+
+	mu sync.Mutex
+	// lru holds the entries, most recently used first.
+	lru     *list.List
+	entries map[string]*list.Element
+	stats   CacheStats
+}
+
+// NewCompileCache returns a cache holding up to size rules, compiled with
+// ops, or DefaultOps if ops is nil. The cache holds at least one rule.
+func NewCompileCache(ops OpsSet, size int) *CompileCache {
+	if ops == nil {
+		ops = DefaultOps
+	}
+	if size < 1 {
+		size = 1
+	}
+	return &CompileCache{
+		ops:     ops,
+		size:    size,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Compile returns the compiled rule, from the cache if it is held. Rules
+// that fail to compile are not cached.
+func (cc *CompileCache) Compile(c *Clause) (ClauseFunc, error) {
+	key := c.Hash()
+
+	cc.mu.Lock()
+	if e, ok := cc.entries[key]; ok {
+		cc.stats.Hits++
+		cc.lru.MoveToFront(e)
+		cf := e.Value.(*cacheEntry).cf
+		cc.mu.Unlock()
+		return cf, nil
+	}
+	cc.stats.Misses++
+	cc.mu.Unlock()
+
+	// Compile without holding the lock, so other rules can be served.
+	cf, err := cc.ops.Compile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if e, ok := cc.entries[key]; ok {
+		// Compiled concurrently by another caller.
+		cc.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).cf, nil
+	}
+	cc.entries[key] = cc.lru.PushFront(&cacheEntry{key: key, cf: cf})
+	for cc.lru.Len() > cc.size {
+		e := cc.lru.Back()
+		cc.lru.Remove(e)
+		delete(cc.entries, e.Value.(*cacheEntry).key)
+		cc.stats.Evictions++
+	}
+	return cf, nil
+}
+
+// Stats returns the cache's metrics.
+func (cc *CompileCache) Stats() CacheStats {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	stats := cc.stats
+	stats.Size = cc.lru.Len()
+	return stats
+}
@@ -0,0 +1,175 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileCache(t *testing.T) {
+	type test struct {
+		name   string
+		rules  []string
+		size   int
+		expect CacheStats
+	}
+
+	tests := []test{
+		{
+			name: "same-rule",
+			rules: []string{
+				`{"==": [{"var": "a"}, 1]}`,
+				`{"==":[{"var":"a"},1]}`,
+				"{\n\t\"==\": [\n\t\t{\"var\": [\"a\"]},\n\t\t1\n\t]\n}",
+			},
+			size:   2,
+			expect: CacheStats{Hits: 2, Misses: 1, Size: 1},
+		},
+		{
+			name: "numbers",
+			rules: []string{
+				`{"==": [{"var": "a"}, 1]}`,
+				`{"==": [{"var": "a"}, 1.0]}`,
+				`{"==": [{"var": "a"}, 1e0]}`,
+			},
+			size:   2,
+			expect: CacheStats{Hits: 2, Misses: 1, Size: 1},
+		},
+		{
+			name: "literal-key-order",
+			rules: []string{
+				`{"in": [{"var": "a"}, [{"x": 1, "y": 2}]]}`,
+				`{"in": [{"var": "a"}, [{"y": 2, "x": 1}]]}`,
+			},
+			size:   2,
+			expect: CacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+		{
+			name: "different-rules",
+			rules: []string{
+				`{"==": [{"var": "a"}, 1]}`,
+				`{"==": [{"var": "a"}, "1"]}`,
+				`{"===": [{"var": "a"}, 1]}`,
+			},
+			size:   3,
+			expect: CacheStats{Misses: 3, Size: 3},
+		},
+		{
+			name: "negative-zero",
+			rules: []string{
+				`{"/": [1, 0]}`,
+				`{"/": [1, -0]}`,
+				`{"/": [1, -0.0]}`,
+			},
+			size:   2,
+			expect: CacheStats{Hits: 1, Misses: 2, Size: 2},
+		},
+		{
+			name: "evicts-least-recently-used",
+			rules: []string{
+				`{"var": "a"}`,
+				`{"var": "b"}`,
+				`{"var": "a"}`,
+				`{"var": "c"}`,
+				`{"var": "a"}`,
+				`{"var": "b"}`,
+			},
+			size:   2,
+			expect: CacheStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2},
+		},
+		{
+			name: "errors-not-cached",
+			rules: []string{
+				`{"nope": []}`,
+				`{"nope": []}`,
+			},
+			size:   2,
+			expect: CacheStats{Misses: 2},
+		},
+		{
+			name: "minimum-size",
+			rules: []string{
+				`{"var": "a"}`,
+				`{"var": "a"}`,
+			},
+			expect: CacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				cc := NewCompileCache(nil, st.size)
+				for _, r := range st.rules {
+					var c Clause
+					err := json.Unmarshal([]byte(r), &c)
+					assert.NoErrorf(t, err, "unmarshal error")
+
+					cf, err := cc.Compile(&c)
+					expectCf, expectErr := DefaultOps.Compile(&c)
+					if expectErr != nil {
+						assert.EqualError(t, err, expectErr.Error())
+						continue
+					}
+					data := map[string]interface{}{"a": 1.0, "b": "b", "c": []interface{}{}}
+					assert.Equal(t, expectCf(context.Background(), data), cf(context.Background(), data))
+				}
+				assert.Equal(t, st.expect, cc.Stats())
+			})
+		})
+	}
+}
+
+func TestCompileCacheNumberText(t *testing.T) {
+	cc := NewCompileCache(nil, 4)
+	for _, st := range []struct {
+		rule   string
+		expect string
+	}{
+		{`{"cat": [1.0]}`, "1.0"},
+		{`{"cat": [1]}`, "1"},
+		{`{"cat": [1.0]}`, "1.0"},
+	} {
+		var c Clause
+		err := UnmarshalNumbers([]byte(st.rule), &c)
+		assert.NoErrorf(t, err, "unmarshal error")
+
+		cf, err := cc.Compile(&c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, st.expect, cf(context.Background(), nil), st.rule)
+		}
+	}
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Size: 2}, cc.Stats())
+}
+
+func TestCompileCacheConcurrent(t *testing.T) {
+	cc := NewCompileCache(nil, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				n := (i + j) % 6
+				c := &Clause{
+					Operator:  Operator{Name: plusOp},
+					Arguments: Arguments{{Value: float64(n)}, {Value: 1.0}},
+				}
+				cf, err := cc.Compile(c)
+				if assert.NoError(t, err) {
+					assert.Equal(t, float64(n+1), cf(context.Background(), nil), fmt.Sprintf("%d + 1", n))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := cc.Stats()
+	assert.Equal(t, uint64(800), stats.Hits+stats.Misses)
+	assert.Equal(t, 4, stats.Size)
+}
@@ -1,8 +1,10 @@
 package jsonlogic
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 )
 
 // Operator represents a jsonlogic Operator.
@@ -152,6 +154,70 @@ func (c *Clause) UnmarshalJSON(bs []byte) error {
 	return nil
 }
 
+// UnmarshalNumbers parses JSON data as a JsonLogic Clause, as
+// json.Unmarshal does, other than that number literals are kept as
+// json.Number, rather than converted to float64, so they keep the exact
+// text they were written in. This is intended for rules used with
+// DecimalOps.
+func UnmarshalNumbers(bs []byte, c *Clause) error {
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid data after top-level value")
+	}
+	*c = clauseFromValue(raw)
+	return nil
+}
+
+// clauseFromValue builds a Clause from decoded JSON, following the same
+// rules as UnmarshalJSON.
+func clauseFromValue(raw interface{}) Clause {
+	if m, ok := raw.(map[string]interface{}); ok && len(m) == 1 {
+		for k, v := range m {
+			return Clause{
+				Operator:  Operator{Name: k},
+				Arguments: argumentsFromValue(v),
+			}
+		}
+	}
+	if rawslice, ok := raw.([]interface{}); ok {
+		if len(rawslice) == 0 {
+			raw = make([]interface{}, 0, 1)
+		}
+		if len(rawslice) != 0 && sliceHasPossibleClause(rawslice) {
+			return Clause{Arguments: argumentsFromValue(rawslice)}
+		}
+	}
+	return Clause{
+		Arguments: []Argument{{
+			Value: raw,
+		}},
+	}
+}
+
+// argumentsFromValue builds Arguments from decoded JSON, following the
+// same rules as Arguments.UnmarshalJSON.
+func argumentsFromValue(raw interface{}) Arguments {
+	switch v := raw.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		args := make(Arguments, len(v))
+		for i, a := range v {
+			c := clauseFromValue(a)
+			args[i] = Argument{Clause: &c}
+		}
+		return args
+	default:
+		c := clauseFromValue(v)
+		return Arguments{{Clause: &c}}
+	}
+}
+
 // MarshalJSON implements json.Marshaler. It enforces
 // rending of clause arguments as an array (even if there was
 // just one non array argument in the original clause when
@@ -159,6 +225,10 @@ func (c *Clause) UnmarshalJSON(bs []byte) error {
 func (c Clause) MarshalJSON() ([]byte, error) {
 	switch c.Operator.Name {
 	case "":
+		if len(c.Arguments) == 0 {
+			// An empty array used as a clause
+			return []byte("[]"), nil
+		}
 		if c.Arguments[0].Clause == nil {
 			return json.Marshal(c.Arguments[0].Value)
 		}
@@ -111,6 +111,11 @@ func TestClauseMarshal(t *testing.T) {
 			rule:      `[{ "var" : "a" }]`,
 			marshalTo: `[{"var":["a"]}]`,
 		},
+		{
+			name:      "empty-null-clause",
+			rule:      `{"":[]}`,
+			marshalTo: `[]`,
+		},
 	}
 
 	for _, st := range tests {
@@ -167,6 +172,22 @@ func TestClauseEval(t *testing.T) {
 			data:   nil,
 			expect: nil,
 		},
+		{
+			name:   "empty-null-clause",
+			rule:   `{"":[]}`,
+			expect: []interface{}{},
+		},
+		{
+			name:   "ternary-no-args",
+			rule:   `{"?:":[]}`,
+			expect: nil,
+		},
+		{
+			name:   "ternary-one-arg",
+			rule:   `{"?:":[{"var":"a"}]}`,
+			data:   map[string]interface{}{"a": "b"},
+			expect: "b",
+		},
 		{
 			name:   "equal-nil",
 			rule:   `{"==":[{"var":"c"},{"var":"c"}]}`,
@@ -1250,3 +1271,48 @@ func BenchmarkClause_testsuite(b *testing.B) {
 		}
 	}
 }
+
+func TestUnmarshalNumbers(t *testing.T) {
+	tests := []json.RawMessage{}
+	bs, err := os.ReadFile("testdata/tests.json")
+	if err != nil {
+		t.Fatalf("could not open testfile, %v", err)
+	}
+	if err = json.Unmarshal(bs, &tests); err != nil {
+		t.Fatalf("could not unmarshal testdata, %v", err)
+	}
+
+	// reencode marshals a clause, and unmarshals it as plain JSON, so
+	// json.Numbers and float64s with the same value compare equal.
+	reencode := func(c *Clause) interface{} {
+		bs, err := json.Marshal(c)
+		assert.NoError(t, err)
+		var v interface{}
+		assert.NoError(t, json.Unmarshal(bs, &v))
+		return v
+	}
+
+	for i, tline := range tests {
+		var details [3]json.RawMessage
+		if err := json.Unmarshal(tline, &details); err != nil {
+			continue
+		}
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var expect, c Clause
+			err := json.Unmarshal(details[0], &expect)
+			assert.NoErrorf(t, err, "unmarshal error")
+			err = UnmarshalNumbers(details[0], &c)
+			assert.NoErrorf(t, err, "unmarshal numbers error")
+			assert.Equal(t, reencode(&expect), reencode(&c))
+		})
+	}
+
+	var c Clause
+	err = UnmarshalNumbers([]byte(`{"in":[1.50,[{"var":"a"},2e0]]}`), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("1.50"), c.Arguments[0].Clause.Arguments[0].Value)
+	assert.Equal(t, json.Number("2e0"), c.Arguments[1].Clause.Arguments[1].Clause.Arguments[0].Value)
+
+	assert.Error(t, UnmarshalNumbers([]byte(`{"var":"a"} 1`), &c))
+	assert.Error(t, UnmarshalNumbers([]byte(`{"var":`), &c))
+}
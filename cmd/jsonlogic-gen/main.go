@@ -0,0 +1,83 @@
+// Command jsonlogic-gen generates a Go function from a jsonlogic rule, with
+// the same semantics as the rule compiled with jsonlogic.Compile. It is
+// intended to be run by go generate, for example
+//
+//	//go:generate go run github.com/QubitProducts/jsonlogic/cmd/jsonlogic-gen -rule eligible.json -func IsEligible -fixtures eligible_fixtures.json
+//
+// which writes the function to eligible.go and, as fixtures are given, a
+// test to eligible_test.go checking the function against the compiled rule
+// for each of the data values in the JSON array in eligible_fixtures.json.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/QubitProducts/jsonlogic"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("jsonlogic-gen: ")
+
+	rulePath := flag.String("rule", "", "`file` holding the JSON rule")
+	funcName := flag.String("func", "", "`name` of the generated function")
+	pkgName := flag.String("pkg", os.Getenv("GOPACKAGE"), "`package` name, defaulting to $GOPACKAGE as set by go generate")
+	output := flag.String("o", "", "output `file`, defaulting to the rule file with a .go extension")
+	fixturesPath := flag.String("fixtures", "", "`file` holding a JSON array of data values to generate a test for")
+	flag.Parse()
+
+	if *rulePath == "" || *funcName == "" || *pkgName == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(*rulePath, filepath.Ext(*rulePath)) + ".go"
+	}
+
+	if err := run(*rulePath, *fixturesPath, *output, jsonlogic.GoOptions{Package: *pkgName, Func: *funcName}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(rulePath, fixturesPath, output string, opts jsonlogic.GoOptions) error {
+	bs, err := os.ReadFile(rulePath)
+	if err != nil {
+		return err
+	}
+	var c jsonlogic.Clause
+	if err := json.Unmarshal(bs, &c); err != nil {
+		return fmt.Errorf("could not unmarshal rule %s, %w", rulePath, err)
+	}
+
+	src, err := jsonlogic.GenerateGo(&c, opts)
+	if err != nil {
+		return fmt.Errorf("could not generate code for %s, %w", rulePath, err)
+	}
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		return err
+	}
+
+	if fixturesPath == "" {
+		return nil
+	}
+	bs, err = os.ReadFile(fixturesPath)
+	if err != nil {
+		return err
+	}
+	var fixtures []interface{}
+	if err := json.Unmarshal(bs, &fixtures); err != nil {
+		return fmt.Errorf("could not unmarshal fixtures %s, %w", fixturesPath, err)
+	}
+
+	src, err = jsonlogic.GenerateGoTest(&c, opts, fixtures)
+	if err != nil {
+		return fmt.Errorf("could not generate test for %s, %w", rulePath, err)
+	}
+	return os.WriteFile(strings.TrimSuffix(output, ".go")+"_test.go", src, 0644)
+}
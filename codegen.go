@@ -0,0 +1,791 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// goImportPath is the import path of this package, as used by generated
+// code.
+const goImportPath = "github.com/QubitProducts/jsonlogic"
+
+// GoOptions configures GenerateGo and GenerateGoTest.
+type GoOptions struct {
+	// Package is the name of the package the code is generated in.
+	Package string
+	// Func is the name of the generated function. Package level helpers
+	// are prefixed with it, so several functions can be generated in the
+	// one package.
+	Func string
+}
+
+func (opts GoOptions) validate() error {
+	if !token.IsIdentifier(opts.Package) {
+		return fmt.Errorf("invalid package name %q", opts.Package)
+	}
+	if !token.IsIdentifier(opts.Func) {
+		return fmt.Errorf("invalid function name %q", opts.Func)
+	}
+	return nil
+}
+
+// GenerateGo generates the source of a Go file declaring a function with
+// the signature of a ClauseFunc, that evaluates c with the semantics of
+// DefaultOps.
+//
+// The var, if, ?:, and, or, !, !!, equality, comparison, +, -, *, /, %,
+// min, max and cat operations, and in on a constant array, are generated as
+// Go code using DottedRef, IsTrue, IsSoftEqual, IsEqual, IsDeepEqual,
+// Compare, ToNumber and ToString. Any other operation, such as map or
+// substr, is compiled with Compile when the package is initialised, and
+// called from the generated code. Such operations, and array and object
+// constants, are embedded as JSON, so must survive a JSON round trip, as
+// any rule that was unmarshaled does.
+func GenerateGo(c *Clause, opts GoOptions) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	// Report unknown operations, or bad arguments, as Compile would.
+	if _, err := Compile(c); err != nil {
+		return nil, err
+	}
+
+	g := &goGenerator{
+		prefix:  lowerFirst(opts.Func),
+		imports: map[string]bool{"context": true},
+	}
+	res, err := g.clause(c)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := marshalUnescaped(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated from a jsonlogic rule. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+	writeGoImports(&buf, g.imports)
+	fmt.Fprintf(&buf, "// %s evaluates the rule\n//\n//\t%s\n", opts.Func, rule)
+	fmt.Fprintf(&buf, "func %s(ctx context.Context, data interface{}) interface{} {\n", opts.Func)
+	buf.Write(g.body.Bytes())
+	fmt.Fprintf(&buf, "return %s\n}\n", res)
+	if g.decls.Len() != 0 {
+		fmt.Fprintf(&buf, "\nvar (\n%s)\n", g.decls.Bytes())
+	}
+	g.writeHelpers(&buf)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code, %w", err)
+	}
+	return src, nil
+}
+
+// GenerateGoTest generates the source of a Go test file, to accompany the
+// output of GenerateGo, that checks the generated function gives the same
+// results as the compiled rule for each of the fixtures.
+func GenerateGoTest(c *Clause, opts GoOptions, fixtures []interface{}) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	rule, err := marshalUnescaped(c)
+	if err != nil {
+		return nil, err
+	}
+	if fixtures == nil {
+		fixtures = []interface{}{}
+	}
+	fixturesJSON, err := marshalUnescaped(fixtures)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling fixtures, %w", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated from a jsonlogic rule. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+	writeGoImports(&buf, map[string]bool{
+		"context":       true,
+		"encoding/json": true,
+		"fmt":           true,
+		"testing":       true,
+		goImportPath:    true,
+	})
+	fmt.Fprintf(&buf, `func Test%s(t *testing.T) {
+	var c jsonlogic.Clause
+	if err := json.Unmarshal([]byte(%s), &c); err != nil {
+		t.Fatalf("could not unmarshal rule, %%v", err)
+	}
+	cf, err := jsonlogic.Compile(&c)
+	if err != nil {
+		t.Fatalf("could not compile rule, %%v", err)
+	}
+
+	var fixtures []interface{}
+	if err := json.Unmarshal([]byte(%s), &fixtures); err != nil {
+		t.Fatalf("could not unmarshal fixtures, %%v", err)
+	}
+
+	ctx := context.Background()
+	for i, data := range fixtures {
+		want := fmt.Sprintf("%%#v", cf(ctx, data))
+		got := fmt.Sprintf("%%#v", %s(ctx, data))
+		if got != want {
+			t.Errorf("fixture %%d: got %%s, want %%s", i, got, want)
+		}
+	}
+}
+`, upperFirst(opts.Func), goStringLiteral(string(rule)), goStringLiteral(string(fixturesJSON)), opts.Func)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code, %w", err)
+	}
+	return src, nil
+}
+
+func writeGoImports(buf *bytes.Buffer, imports map[string]bool) {
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	// Standard library imports first, as goimports would.
+	fmt.Fprintf(buf, "import (\n")
+	for _, p := range paths {
+		if p != goImportPath {
+			fmt.Fprintf(buf, "%q\n", p)
+		}
+	}
+	if imports[goImportPath] {
+		fmt.Fprintf(buf, "\n%q\n", goImportPath)
+	}
+	fmt.Fprintf(buf, ")\n\n")
+}
+
+// marshalUnescaped marshals v, undoing the escaping of <, > and & by
+// json.Marshal, which obscures comparisons in rules.
+func marshalUnescaped(v interface{}) ([]byte, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(bs))
+	for i := 0; i < len(bs); i++ {
+		if bs[i] != '\\' || i+1 == len(bs) {
+			out = append(out, bs[i])
+			continue
+		}
+		if i+6 <= len(bs) {
+			switch string(bs[i+1 : i+6]) {
+			case "u003c":
+				out = append(out, '<')
+				i += 5
+				continue
+			case "u003e":
+				out = append(out, '>')
+				i += 5
+				continue
+			case "u0026":
+				out = append(out, '&')
+				i += 5
+				continue
+			}
+		}
+		// Any other escape, which may be an escaped backslash.
+		out = append(out, bs[i], bs[i+1])
+		i++
+	}
+	return out, nil
+}
+
+// goStringLiteral quotes s as a Go string literal, preferring a raw string.
+func goStringLiteral(s string) string {
+	if !strings.ContainsAny(s, "`\r") && utf8.ValidString(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// goGenerator generates the body of a function evaluating a rule. Each
+// argument is generated as statements, written to body, followed by an
+// expression that is either a literal, or the name of a variable those
+// statements assign, so arguments are evaluated in the same order as by
+// the compiled rule.
+type goGenerator struct {
+	prefix  string
+	body    bytes.Buffer
+	decls   bytes.Buffer
+	imports map[string]bool
+	helpers map[string]bool
+	n       int
+}
+
+func (g *goGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+	g.body.WriteByte('\n')
+}
+
+// name returns a new unique name, with the given base.
+func (g *goGenerator) name(base string) string {
+	g.n++
+	return fmt.Sprintf("%s%d", base, g.n)
+}
+
+// helper records the use of a package level helper, and returns its name.
+func (g *goGenerator) helper(name string) string {
+	if g.helpers == nil {
+		g.helpers = map[string]bool{}
+	}
+	g.helpers[name] = true
+	switch name {
+	case "Var":
+		g.imports[goImportPath] = true
+	case "Decode":
+		g.imports["encoding/json"] = true
+	case "Plus", "Minus", "Multiply", "Min", "Max":
+		g.imports["math"] = true
+		g.imports[goImportPath] = true
+	case "Compile":
+		g.imports["encoding/json"] = true
+		g.imports[goImportPath] = true
+	}
+	return g.prefix + name
+}
+
+// jsonlogic returns the qualified name of an identifier in this package.
+func (g *goGenerator) jsonlogic(ident string) string {
+	g.imports[goImportPath] = true
+	return "jsonlogic." + ident
+}
+
+func (g *goGenerator) arg(arg Argument) (string, error) {
+	if arg.Clause == nil {
+		return g.constant(arg.Value)
+	}
+	return g.clause(arg.Clause)
+}
+
+func (g *goGenerator) args(args Arguments) ([]string, error) {
+	exprs := make([]string, len(args))
+	for i, a := range args {
+		expr, err := g.arg(a)
+		if err != nil {
+			return nil, err
+		}
+		exprs[i] = expr
+	}
+	return exprs, nil
+}
+
+func (g *goGenerator) constant(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return strconv.Quote(v), nil
+	case float64:
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			return "", fmt.Errorf("constant %v can not be generated", v)
+		case v == 0 && math.Signbit(v):
+			// -0 can not be written as a constant expression.
+			g.imports["math"] = true
+			return "math.Copysign(0, -1)", nil
+		}
+		return "float64(" + strconv.FormatFloat(v, 'g', -1, 64) + ")", nil
+	case []interface{}, map[string]interface{}:
+		bs, err := marshalUnescaped(v)
+		var rt interface{}
+		if err == nil {
+			err = json.Unmarshal(bs, &rt)
+		}
+		if err != nil || !reflect.DeepEqual(v, rt) {
+			return "", fmt.Errorf("constant %v does not survive a json round trip, so can not be generated", v)
+		}
+		name := g.name(g.prefix + "Const")
+		fmt.Fprintf(&g.decls, "%s = %s(%s)\n", name, g.helper("Decode"), goStringLiteral(string(bs)))
+		return name, nil
+	default:
+		return "", fmt.Errorf("constant %v of type %T can not be generated", v, v)
+	}
+}
+
+func (g *goGenerator) clause(c *Clause) (string, error) {
+	args := c.Arguments
+	switch c.Operator.Name {
+	case nullOp:
+		switch {
+		case len(args) == 0:
+			v := g.name("v")
+			g.printf("%s := []interface{}{}", v)
+			return v, nil
+		case args[0].Clause == nil:
+			return g.constant(args[0].Value)
+		}
+		exprs, err := g.args(args)
+		if err != nil {
+			return "", err
+		}
+		v := g.name("v")
+		g.printf("%s := []interface{}{%s}", v, strings.Join(exprs, ", "))
+		return v, nil
+	case varOp:
+		if len(args) == 0 {
+			return "data", nil
+		}
+		index, err := g.arg(args[0])
+		if err != nil {
+			return "", err
+		}
+		def := "nil"
+		if len(args) >= 2 {
+			if def, err = g.arg(args[1]); err != nil {
+				return "", err
+			}
+		}
+		v := g.name("v")
+		g.printf("%s := %s(data, %s, %s)", v, g.helper("Var"), index, def)
+		return v, nil
+	case ifOp, ternaryOp:
+		switch {
+		case len(args) == 0:
+			return "nil", nil
+		case len(args) == 1:
+			return g.arg(args[0])
+		case len(args) <= 3 || c.Operator.Name == ternaryOp:
+			return g.if3(args)
+		default:
+			v := g.name("v")
+			g.printf("var %s interface{}", v)
+			if err := g.ifMulti(v, args); err != nil {
+				return "", err
+			}
+			return v, nil
+		}
+	case andOp, orOp:
+		if len(args) == 0 {
+			return "nil", nil
+		}
+		v := g.name("v")
+		g.printf("var %s interface{}", v)
+		if err := g.shortCircuit(v, c.Operator.Name == andOp, args); err != nil {
+			return "", err
+		}
+		return v, nil
+	case negateOp, doubleNegateOp:
+		if len(args) == 0 {
+			return strconv.FormatBool(c.Operator.Name == negateOp), nil
+		}
+		expr, err := g.arg(args[0])
+		if err != nil {
+			return "", err
+		}
+		not := ""
+		if c.Operator.Name == negateOp {
+			not = "!"
+		}
+		v := g.name("v")
+		g.printf("%s := %s%s(%s)", v, not, g.jsonlogic("IsTrue"), expr)
+		return v, nil
+	case equalOp, equalThreeOp, notEqualOp, notEqualThreeOp:
+		eq := "IsSoftEqual"
+		if c.Operator.Name == equalThreeOp || c.Operator.Name == notEqualThreeOp {
+			eq = "IsEqual"
+		}
+		not := ""
+		if c.Operator.Name == notEqualOp || c.Operator.Name == notEqualThreeOp {
+			not = "!"
+		}
+		switch len(args) {
+		case 0:
+			return strconv.FormatBool(not == ""), nil
+		case 1:
+			return strconv.FormatBool(not != ""), nil
+		}
+		exprs, err := g.args(args[:2])
+		if err != nil {
+			return "", err
+		}
+		v := g.name("v")
+		g.printf("%s := %s%s(%s, %s)", v, not, g.jsonlogic(eq), exprs[0], exprs[1])
+		return v, nil
+	case lessOp, lessEqOp, greaterOp, greaterEqOp:
+		return g.comparison(c.Operator.Name, args)
+	case plusOp, minusOp, multiplyOp, minOp, maxOp:
+		return g.arithmetic(c.Operator.Name, args)
+	case divideOp, moduloOp:
+		if len(args) < 2 {
+			return "nil", nil
+		}
+		exprs, err := g.args(args[:2])
+		if err != nil {
+			return "", err
+		}
+		l, r := g.jsonlogic("ToNumber")+"("+exprs[0]+")", g.jsonlogic("ToNumber")+"("+exprs[1]+")"
+		v := g.name("v")
+		if c.Operator.Name == divideOp {
+			g.printf("%s := %s / %s", v, l, r)
+		} else {
+			g.imports["math"] = true
+			g.printf("%s := math.Mod(%s, %s)", v, l, r)
+		}
+		return v, nil
+	case catOp:
+		if len(args) == 0 {
+			return `""`, nil
+		}
+		exprs, err := g.args(args)
+		if err != nil {
+			return "", err
+		}
+		for i, e := range exprs {
+			exprs[i] = g.jsonlogic("ToString") + "(" + e + ")"
+		}
+		v := g.name("v")
+		g.printf("%s := %s", v, strings.Join(exprs, " + "))
+		return v, nil
+	case inOp:
+		if len(args) >= 2 {
+			if haystack, ok := constantArg(args[1]); ok {
+				if _, ok := haystack.([]interface{}); ok {
+					return g.inArray(args[0], haystack)
+				}
+			}
+		}
+		return g.compiled(c)
+	default:
+		return g.compiled(c)
+	}
+}
+
+// compiled generates a call to the compiled clause.
+func (g *goGenerator) compiled(c *Clause) (string, error) {
+	bs, err := marshalUnescaped(c)
+	if err != nil {
+		return "", err
+	}
+	name := g.name(g.prefix + "Op")
+	fmt.Fprintf(&g.decls, "%s = %s(%s)\n", name, g.helper("Compile"), goStringLiteral(string(bs)))
+	v := g.name("v")
+	g.printf("%s := %s(ctx, data)", v, name)
+	return v, nil
+}
+
+// if3 generates an if with up to three arguments, all of which are
+// evaluated.
+func (g *goGenerator) if3(args Arguments) (string, error) {
+	exprs, err := g.args(args[:2])
+	if err != nil {
+		return "", err
+	}
+	r := "nil"
+	if len(args) == 3 {
+		if r, err = g.arg(args[2]); err != nil {
+			return "", err
+		}
+	}
+	v := g.name("v")
+	g.printf("var %s interface{}", v)
+	g.printf("if %s(%s) {", g.jsonlogic("IsTrue"), exprs[0])
+	g.printf("%s = %s", v, exprs[1])
+	g.printf("} else {")
+	g.printf("%s = %s", v, r)
+	g.printf("}")
+	return v, nil
+}
+
+// ifMulti generates an if with more than three arguments, assigning the
+// result to v, and evaluating conditions in turn.
+func (g *goGenerator) ifMulti(v string, args Arguments) error {
+	if len(args) < 2 {
+		if len(args) == 1 {
+			expr, err := g.arg(args[0])
+			if err != nil {
+				return err
+			}
+			g.printf("%s = %s", v, expr)
+		}
+		return nil
+	}
+
+	cond, err := g.arg(args[0])
+	if err != nil {
+		return err
+	}
+	g.printf("if %s(%s) {", g.jsonlogic("IsTrue"), cond)
+	expr, err := g.arg(args[1])
+	if err != nil {
+		return err
+	}
+	g.printf("%s = %s", v, expr)
+	if len(args) > 2 {
+		g.printf("} else {")
+		if err := g.ifMulti(v, args[2:]); err != nil {
+			return err
+		}
+	}
+	g.printf("}")
+	return nil
+}
+
+// shortCircuit generates an and or an or, assigning the result to v.
+func (g *goGenerator) shortCircuit(v string, and bool, args Arguments) error {
+	expr, err := g.arg(args[0])
+	if err != nil {
+		return err
+	}
+	g.printf("%s = %s", v, expr)
+	if len(args) == 1 {
+		return nil
+	}
+
+	not := "!"
+	if and {
+		not = ""
+	}
+	g.printf("if %s%s(%s) {", not, g.jsonlogic("IsTrue"), v)
+	if err := g.shortCircuit(v, and, args[1:]); err != nil {
+		return err
+	}
+	g.printf("}")
+	return nil
+}
+
+var goCompareOps = map[string]string{
+	lessOp:      "<",
+	lessEqOp:    "<=",
+	greaterOp:   ">",
+	greaterEqOp: ">=",
+}
+
+func (g *goGenerator) comparison(op string, args Arguments) (string, error) {
+	if len(args) < 2 {
+		return "false", nil
+	}
+	if len(args) >= 3 && (op == lessOp || op == lessEqOp) {
+		// A between test, lower < var < upper
+		exprs, err := g.args(args[:3])
+		if err != nil {
+			return "", err
+		}
+		lc, lok := g.name("c"), g.name("ok")
+		rc, rok := g.name("c"), g.name("ok")
+		v := g.name("v")
+		g.printf("%s, %s := %s(%s, %s)", lc, lok, g.jsonlogic("Compare"), exprs[0], exprs[1])
+		g.printf("%s, %s := %s(%s, %s)", rc, rok, g.jsonlogic("Compare"), exprs[1], exprs[2])
+		g.printf("%s := %s && %s && %s %s 0 && %s %s 0", v, lok, rok, lc, goCompareOps[op], rc, goCompareOps[op])
+		return v, nil
+	}
+
+	exprs, err := g.args(args[:2])
+	if err != nil {
+		return "", err
+	}
+	c, ok := g.name("c"), g.name("ok")
+	v := g.name("v")
+	g.printf("%s, %s := %s(%s, %s)", c, ok, g.jsonlogic("Compare"), exprs[0], exprs[1])
+	g.printf("%s := %s && %s %s 0", v, ok, c, goCompareOps[op])
+	return v, nil
+}
+
+// goArithmeticHelpers are the helpers used for operations on any number
+// of arguments.
+var goArithmeticHelpers = map[string]string{
+	plusOp:     "Plus",
+	minusOp:    "Minus",
+	multiplyOp: "Multiply",
+	minOp:      "Min",
+	maxOp:      "Max",
+}
+
+// arithmetic generates a call to the helper for +, -, *, min or max.
+func (g *goGenerator) arithmetic(op string, args Arguments) (string, error) {
+	if len(args) == 0 && op != plusOp {
+		return "nil", nil
+	}
+	exprs, err := g.args(args)
+	if err != nil {
+		return "", err
+	}
+	v := g.name("v")
+	g.printf("%s := %s(%s)", v, g.helper(goArithmeticHelpers[op]), strings.Join(exprs, ", "))
+	return v, nil
+}
+
+// inArray generates an in of a value in a constant array.
+func (g *goGenerator) inArray(needle Argument, haystack interface{}) (string, error) {
+	expr, err := g.arg(needle)
+	if err != nil {
+		return "", err
+	}
+	arr, err := g.constant(haystack)
+	if err != nil {
+		return "", err
+	}
+	v := g.name("v")
+	g.printf("%s := false", v)
+	g.printf("for _, h := range %s.([]interface{}) {", arr)
+	g.printf("if %s(%s, h) {", g.jsonlogic("IsDeepEqual"), expr)
+	g.printf("%s = true", v)
+	g.printf("break")
+	g.printf("}")
+	g.printf("}")
+	return v, nil
+}
+
+func (g *goGenerator) writeHelpers(buf *bytes.Buffer) {
+	if g.helpers["Var"] {
+		fmt.Fprintf(buf, `
+// %[1]sVar looks up a var, as the var operation does.
+func %[1]sVar(data, index, def interface{}) interface{} {
+	if s, ok := index.(string); ok && s == "" {
+		return data
+	}
+	switch data.(type) {
+	case map[string]interface{}, []interface{}:
+		if v := jsonlogic.DottedRef(data, index); v != nil {
+			return v
+		}
+	}
+	return def
+}
+`, g.prefix)
+	}
+	if g.helpers["Plus"] {
+		fmt.Fprintf(buf, `
+func %[1]sPlus(vs ...interface{}) interface{} {
+	res := 0.0
+	for _, v := range vs {
+		n := jsonlogic.ToNumber(v)
+		if math.IsNaN(n) {
+			return n
+		}
+		res += n
+	}
+	return res
+}
+`, g.prefix)
+	}
+	if g.helpers["Minus"] {
+		fmt.Fprintf(buf, `
+func %[1]sMinus(vs ...interface{}) interface{} {
+	res := jsonlogic.ToNumber(vs[0])
+	switch {
+	case math.IsNaN(res):
+		return res
+	case len(vs) == 1:
+		return -res
+	}
+	for _, v := range vs[1:] {
+		n := jsonlogic.ToNumber(v)
+		if math.IsNaN(n) {
+			return res
+		}
+		res -= n
+	}
+	return res
+}
+`, g.prefix)
+	}
+	if g.helpers["Multiply"] {
+		fmt.Fprintf(buf, `
+func %[1]sMultiply(vs ...interface{}) interface{} {
+	res := 1.0
+	for _, v := range vs {
+		n := jsonlogic.ToNumber(v)
+		if math.IsNaN(n) {
+			return n
+		}
+		res *= n
+	}
+	return res
+}
+`, g.prefix)
+	}
+	if g.helpers["Min"] {
+		fmt.Fprintf(buf, `
+func %[1]sMin(vs ...interface{}) interface{} {
+	res := math.Inf(1)
+	for _, v := range vs {
+		n := jsonlogic.ToNumber(v)
+		if math.IsNaN(n) {
+			return n
+		}
+		if n < res {
+			res = n
+		}
+	}
+	return res
+}
+`, g.prefix)
+	}
+	if g.helpers["Max"] {
+		fmt.Fprintf(buf, `
+func %[1]sMax(vs ...interface{}) interface{} {
+	res := math.Inf(-1)
+	for _, v := range vs {
+		n := jsonlogic.ToNumber(v)
+		if math.IsNaN(n) {
+			return n
+		}
+		if n > res {
+			res = n
+		}
+	}
+	return res
+}
+`, g.prefix)
+	}
+	if g.helpers["Decode"] {
+		fmt.Fprintf(buf, `
+func %[1]sDecode(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		panic(err)
+	}
+	return v
+}
+`, g.prefix)
+	}
+	if g.helpers["Compile"] {
+		fmt.Fprintf(buf, `
+func %[1]sCompile(rule string) jsonlogic.ClauseFunc {
+	var c jsonlogic.Clause
+	if err := json.Unmarshal([]byte(rule), &c); err != nil {
+		panic(err)
+	}
+	cf, err := jsonlogic.Compile(&c)
+	if err != nil {
+		panic(err)
+	}
+	return cf
+}
+`, g.prefix)
+	}
+}
@@ -0,0 +1,184 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateGo(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		clause *Clause
+		opts   GoOptions
+		err    string
+	}
+
+	opts := GoOptions{Package: "rules", Func: "Eval"}
+
+	// The expected source for each test is in
+	// testdata/codegen/<name>.go.golden, and may be regenerated by running
+	// the tests with -update.
+	tests := []test{
+		{name: "constant", rule: `true`, opts: opts},
+		{name: "array-constant", rule: `["a",{"b":1,"c":2}]`, opts: opts},
+		{name: "negative-zero", rule: `{"var":["a",-0]}`, opts: opts},
+		{name: "var", rule: `{"var":["user.name","anon"]}`, opts: opts},
+		{name: "array", rule: `[{"var":"a"},1]`, opts: opts},
+		{name: "and", rule: `{"and":[{">=":[{"var":"age"},18]},{"==":[{"var":"country"},"GB"]}]}`, opts: opts},
+		{name: "or-not", rule: `{"or":[{"!":{"var":"banned"}},{"!==":[{"var":"plan"},"free"]}]}`, opts: opts},
+		{name: "between", rule: `{"<=":[1,{"var":"n"},10]}`, opts: opts},
+		{name: "if", rule: `{"if":[{"var":"vip"},"gold","basic"]}`, opts: opts},
+		{name: "if-multi", rule: `{"if":[{"<":[{"var":"t"},0]},"ice",{"<":[{"var":"t"},100]},"water","steam"]}`, opts: opts},
+		{name: "in-array", rule: `{"in":[{"var":"country"},["GB","IE"]]}`, opts: opts},
+		{name: "arithmetic", rule: `{"<":[{"+":[{"*":[{"var":"price"},{"var":"qty"}]},{"-":[{"var":"discount"}]}]},{"max":[{"/":[{"var":"budget"},2]},{"%":[{"var":"n"},7]},{"min":[1,2]}]}]}`, opts: opts},
+		{name: "compiled", rule: `{"some":[{"var":"items"},{">":[{"var":"qty"},1]}]}`, opts: opts},
+		{
+			name: "prefixed-helpers",
+			rule: `{"==":[{"cat":[{"var":"a"},{"var":"b"}]},"ab"]}`,
+			opts: GoOptions{Package: "segments", Func: "IsMatch"},
+		},
+		{
+			name: "invalid-package",
+			rule: `true`,
+			opts: GoOptions{Package: "my-rules", Func: "Eval"},
+			err:  `invalid package name "my-rules"`,
+		},
+		{
+			name: "invalid-func",
+			rule: `true`,
+			opts: GoOptions{Package: "rules"},
+			err:  `invalid function name ""`,
+		},
+		{
+			name: "unknown-operation",
+			rule: `{"nope":[1]}`,
+			opts: opts,
+			err:  `unrecognized operation nope`,
+		},
+		{
+			name:   "nan-constant",
+			clause: &Clause{Operator: Operator{Name: notEqualOp}, Arguments: Arguments{{Value: math.NaN()}, {Value: 1.0}}},
+			opts:   opts,
+			err:    `constant NaN can not be generated`,
+		},
+		{
+			name:   "int-constant",
+			clause: &Clause{Operator: Operator{Name: equalOp}, Arguments: Arguments{{Value: 1}, {Value: 1.0}}},
+			opts:   opts,
+			err:    `constant 1 of type int can not be generated`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				c := st.clause
+				if c == nil {
+					c = &Clause{}
+					err := json.Unmarshal([]byte(st.rule), c)
+					assert.NoErrorf(t, err, "unmarshal error")
+				}
+
+				src, err := GenerateGo(c, st.opts)
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					return
+				}
+				assert.NoError(t, err)
+
+				golden := filepath.Join("testdata", "codegen", st.name+".go.golden")
+				if *updateGolden {
+					assert.NoError(t, os.WriteFile(golden, src, 0644))
+					return
+				}
+				expect, err := os.ReadFile(golden)
+				if assert.NoError(t, err) {
+					assert.Equal(t, string(expect), string(src))
+				}
+			})
+		})
+	}
+}
+
+// TestGenerateGoTestsuite generates a function for each rule in the
+// official test suite, along with a generated test of it against the rule
+// using the suite's data, and runs the tests.
+func TestGenerateGoTestsuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building generated code in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := os.ReadFile("testdata/tests.json")
+	if err != nil {
+		t.Fatalf("could not open testfile, %v", err)
+	}
+	tests := []json.RawMessage{}
+	if err = json.Unmarshal(bs, &tests); err != nil {
+		t.Fatalf("could not unmarshal testdata, %v", err)
+	}
+
+	dir := t.TempDir()
+	gomod := fmt.Sprintf("module example.com/rules\n\ngo 1.13\n\nrequire %s v0.0.0\n\nreplace %s => %s\n", goImportPath, goImportPath, wd)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644))
+	gosum, err := os.ReadFile("go.sum")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), gosum, 0644))
+
+	for i, tline := range tests {
+		var details [3]json.RawMessage
+		if err := json.Unmarshal(tline, &details); err != nil {
+			continue
+		}
+		var c Clause
+		if err := json.Unmarshal(details[0], &c); err != nil {
+			t.Errorf("could not unmarshal test clause %d, %v", i, err)
+			continue
+		}
+		var data interface{}
+		if err := json.Unmarshal(details[1], &data); err != nil {
+			t.Errorf("could not unmarshal test data %d, %v", i, err)
+			continue
+		}
+
+		opts := GoOptions{Package: "rules", Func: fmt.Sprintf("Rule%d", i)}
+		src, err := GenerateGo(&c, opts)
+		if !assert.NoErrorf(t, err, "generating %s", details[0]) {
+			continue
+		}
+		testSrc, err := GenerateGoTest(&c, opts, []interface{}{data})
+		if !assert.NoErrorf(t, err, "generating test of %s", details[0]) {
+			continue
+		}
+		base := filepath.Join(dir, fmt.Sprintf("rule%d", i))
+		assert.NoError(t, os.WriteFile(base+".go", src, 0644))
+		assert.NoError(t, os.WriteFile(base+"_test.go", testSrc, 0644))
+	}
+
+	cmd := exec.Command(gobin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	assert.NoErrorf(t, err, "go test of generated code failed:\n%s", out)
+}
+
+func TestMarshalUnescaped(t *testing.T) {
+	bs, err := marshalUnescaped(map[string]interface{}{"<": []interface{}{"a<&>", `\u003c`, `\`}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"<":["a<&>","\\u003c","\\"]}`, string(bs))
+}
@@ -0,0 +1,119 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coercionCase is a single conformance case, generated from the behaviour of
+// json-logic-js by testdata/coercion/generate.js. Either fn names one of the
+// coercion functions, or op names an operation to call with args as literal
+// arguments.
+type coercionCase struct {
+	Fn     string          `json:"fn"`
+	Op     string          `json:"op"`
+	Args   json.RawMessage `json:"args"`
+	Expect json.RawMessage `json:"expect"`
+}
+
+// decodeFixture replaces the {"$number": "..."} placeholders used for
+// numbers that can not be represented in JSON.
+func decodeFixture(i interface{}) interface{} {
+	switch v := i.(type) {
+	case []interface{}:
+		for j := range v {
+			v[j] = decodeFixture(v[j])
+		}
+		return v
+	case map[string]interface{}:
+		if n, ok := v["$number"]; ok && len(v) == 1 {
+			switch n {
+			case "NaN":
+				return math.NaN()
+			case "Infinity":
+				return math.Inf(1)
+			case "-Infinity":
+				return math.Inf(-1)
+			case "-0":
+				return math.Copysign(0, -1)
+			}
+		}
+		for k := range v {
+			v[k] = decodeFixture(v[k])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func loadCoercionCases(t *testing.T) []coercionCase {
+	bs, err := os.ReadFile("testdata/coercion/cases.json")
+	if err != nil {
+		t.Fatalf("could not open testfile, %v", err)
+	}
+	var cases []coercionCase
+	if err := json.Unmarshal(bs, &cases); err != nil {
+		t.Fatalf("could not unmarshal testdata, %v", err)
+	}
+	return cases
+}
+
+func sameNumber(l, r float64) bool {
+	if math.IsNaN(l) || math.IsNaN(r) {
+		return math.IsNaN(l) && math.IsNaN(r)
+	}
+	return l == r && math.Signbit(l) == math.Signbit(r)
+}
+
+func TestCoercionConformance(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range loadCoercionCases(t) {
+		var args []interface{}
+		if err := json.Unmarshal(tc.Args, &args); err != nil {
+			t.Fatalf("could not unmarshal args %s, %v", tc.Args, err)
+		}
+		args = decodeFixture(args).([]interface{})
+
+		var expect interface{}
+		if err := json.Unmarshal(tc.Expect, &expect); err != nil {
+			t.Fatalf("could not unmarshal expect %s, %v", tc.Expect, err)
+		}
+		expect = decodeFixture(expect)
+
+		name := tc.Fn + tc.Op + string(tc.Args)
+		switch tc.Fn {
+		case "toNumber":
+			got := toNumber(args[0])
+			assert.Truef(t, sameNumber(expect.(float64), got), "%s: expected %v, got %v", name, expect, got)
+			continue
+		case "toString":
+			assert.Equal(t, expect, toString(args[0]), name)
+			continue
+		case "IsTrue":
+			assert.Equal(t, expect, IsTrue(args[0]), name)
+			continue
+		case "IsSoftEqual":
+			assert.Equal(t, expect, IsSoftEqual(args[0], args[1]), name)
+			continue
+		}
+
+		cls := &Clause{Operator: Operator{Name: tc.Op}}
+		for _, a := range args {
+			cls.Arguments = append(cls.Arguments, Argument{Value: a})
+		}
+		assert.NotPanics(t, func() {
+			cf, err := Compile(cls)
+			if !assert.NoError(t, err, name) {
+				return
+			}
+			assert.Equal(t, expect, cf(ctx, nil), name)
+		}, name)
+	}
+}
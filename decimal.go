@@ -0,0 +1,407 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decimalDivisionDigits is the number of decimal places kept when the
+// result of a division can not be represented exactly as a decimal.
+const decimalDivisionDigits = 34
+
+// DecimalOps is DefaultOps with exact decimal arithmetic. Numbers (of any
+// supported numeric type) are read as exact decimals, so {"+": [0.1, 0.2]}
+// is exactly 0.3. json.Numbers, such as those in data decoded with
+// json.Decoder.UseNumber, or number literals in rules read with
+// UnmarshalNumbers, are read as the decimal they were written as. Rules
+// read with json.Unmarshal hold number literals as float64, which are read
+// via their shortest representation, so literals with more than 15
+// significant digits may not be exact.
+//
+// The +, -, *, /, %, <, <=, >, >=, min, max, == and != operations work on
+// these exact values, and return numbers as json.Number, which keeps them
+// exact when passed to other decimal operations, or when the result is
+// marshaled to JSON. Conversion to float64 only happens when a caller asks
+// for it (e.g. with json.Number.Float64), or when a value is passed to an
+// operation that does not support decimals. Divisions that do not have an
+// exact decimal result are rounded to 34 decimal places. Values that are
+// not numbers, such as "2020-01-01", are compared by <, <=, > and >= as
+// they are by DefaultOps.
+var DecimalOps = decimalOps()
+
+func decimalOps() OpsSet {
+	ops := OpsSet{}
+	for k, v := range DefaultOps {
+		ops[k] = v
+	}
+
+	ops[plusOp] = buildDecimalPlusOp
+	ops[minusOp] = buildDecimalMinusOp
+	ops[multiplyOp] = buildDecimalMultiplyOp
+	ops[divideOp] = buildDecimalDivideOp
+	ops[moduloOp] = buildDecimalModuloOp
+	ops[lessOp] = buildDecimalCompareOp(true, func(c int) bool { return c < 0 })
+	ops[lessEqOp] = buildDecimalCompareOp(true, func(c int) bool { return c <= 0 })
+	ops[greaterOp] = buildDecimalCompareOp(false, func(c int) bool { return c > 0 })
+	ops[greaterEqOp] = buildDecimalCompareOp(false, func(c int) bool { return c >= 0 })
+	ops[minOp] = buildDecimalMinMaxOp(func(c int) bool { return c < 0 })
+	ops[maxOp] = buildDecimalMinMaxOp(func(c int) bool { return c > 0 })
+	ops[equalOp] = buildDecimalEqualOp
+	ops[notEqualOp] = buildDecimalNotEqualOp
+
+	return ops
+}
+
+// parseDecimal parses a decimal string, such as "12.50" or "1e-3". Unlike
+// big.Rat.SetString, fractions such as "1/3" are not accepted.
+func parseDecimal(s string) (*big.Rat, bool) {
+	if strings.ContainsAny(s, "/_") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// toDecimal converts a value to an exact decimal, following the same
+// coercion rules as toNumber. Floats are converted via their shortest
+// decimal representation, so 0.1 is exactly one tenth.
+func toDecimal(i interface{}) (*big.Rat, bool) {
+	switch v := i.(type) {
+	case json.Number:
+		return parseDecimal(v.String())
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		return parseDecimal(strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		tstr := strings.TrimFunc(v, isJSSpace)
+		if tstr == "" {
+			return new(big.Rat), true
+		}
+		return parseDecimal(tstr)
+	case bool:
+		if v {
+			return big.NewRat(1, 1), true
+		}
+		return new(big.Rat), true
+	case []interface{}:
+		if len(v) == 0 {
+			return new(big.Rat), true
+		}
+		if len(v) == 1 {
+			return toDecimal(v[0])
+		}
+		return nil, false
+	case nil:
+		return new(big.Rat), true
+	case float32:
+		return toDecimal(json.Number(strconv.FormatFloat(float64(v), 'g', -1, 32)))
+	default:
+		if str, ok := goNumberToString(v); ok {
+			return parseDecimal(str)
+		}
+		return nil, false
+	}
+}
+
+// decimalResult renders a decimal as a json.Number. Values with a finite
+// decimal expansion are rendered exactly.
+func decimalResult(r *big.Rat) json.Number {
+	if r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+
+	// A fraction has a finite decimal expansion if its denominator has no
+	// prime factors other than 2 and 5. The number of decimal places
+	// needed is the larger of the two powers.
+	d := new(big.Int).Set(r.Denom())
+	twos := d.TrailingZeroBits()
+	d.Rsh(d, twos)
+
+	fives := uint(0)
+	five := big.NewInt(5)
+	q, m := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(d, five, m)
+		if m.Sign() != 0 {
+			break
+		}
+		d.Set(q)
+		fives++
+	}
+
+	if d.IsInt64() && d.Int64() == 1 {
+		places := twos
+		if fives > places {
+			places = fives
+		}
+		return json.Number(r.FloatString(int(places)))
+	}
+
+	str := r.FloatString(decimalDivisionDigits)
+	str = strings.TrimRight(str, "0")
+	str = strings.TrimSuffix(str, ".")
+	if str == "-0" {
+		str = "0"
+	}
+	return json.Number(str)
+}
+
+// buildDecimalFoldOp builds an operation that combines all of its
+// arguments in turn.
+func buildDecimalFoldOp(args Arguments, ops OpsSet, f func(acc, item *big.Rat)) (ClauseFunc, error) {
+	termArgs, err := buildTermArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		resp, ok := toDecimal(termArgs[0](ctx, data))
+		if !ok {
+			return math.NaN()
+		}
+		for _, ta := range termArgs[1:] {
+			item, ok := toDecimal(ta(ctx, data))
+			if !ok {
+				return math.NaN()
+			}
+			f(resp, item)
+		}
+		return decimalResult(resp)
+	}, nil
+}
+
+func buildDecimalPlusOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return func(context.Context, interface{}) interface{} {
+			return json.Number("0")
+		}, nil
+	}
+	return buildDecimalFoldOp(args, ops, func(acc, item *big.Rat) {
+		acc.Add(acc, item)
+	})
+}
+
+func buildDecimalMinusOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	switch {
+	case len(args) == 0:
+		return nullf, nil
+	case len(args) == 1:
+		arg, err := BuildArgFunc(args[0], ops)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, data interface{}) interface{} {
+			item, ok := toDecimal(arg(ctx, data))
+			if !ok {
+				return math.NaN()
+			}
+			return decimalResult(item.Neg(item))
+		}, nil
+	}
+	return buildDecimalFoldOp(args, ops, func(acc, item *big.Rat) {
+		acc.Sub(acc, item)
+	})
+}
+
+func buildDecimalMultiplyOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+	return buildDecimalFoldOp(args, ops, func(acc, item *big.Rat) {
+		acc.Mul(acc, item)
+	})
+}
+
+// buildDecimalBinaryOp builds an operation on exactly two arguments.
+func buildDecimalBinaryOp(args Arguments, ops OpsSet, f func(l, r *big.Rat) interface{}) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lVal, lok := toDecimal(lArg(ctx, data))
+		rVal, rok := toDecimal(rArg(ctx, data))
+		if !lok || !rok {
+			return math.NaN()
+		}
+		return f(lVal, rVal)
+	}, nil
+}
+
+func buildDecimalDivideOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return buildDecimalBinaryOp(args, ops, func(l, r *big.Rat) interface{} {
+		if r.Sign() == 0 {
+			// as per float64 division
+			switch l.Sign() {
+			case 0:
+				return math.NaN()
+			default:
+				return math.Inf(l.Sign())
+			}
+		}
+		return decimalResult(l.Quo(l, r))
+	})
+}
+
+func buildDecimalModuloOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return buildDecimalBinaryOp(args, ops, func(l, r *big.Rat) interface{} {
+		if r.Sign() == 0 {
+			return math.NaN()
+		}
+		// l - r*trunc(l/r), so that the result has the sign of l, as per
+		// math.Mod.
+		q := new(big.Rat).Quo(l, r)
+		t := new(big.Int).Quo(q.Num(), q.Denom())
+		q.SetInt(t)
+		q.Mul(q, r)
+		return decimalResult(l.Sub(l, q))
+	})
+}
+
+// compareDecimals compares two values as exact decimals. Values that can
+// not be converted to decimals, such as non-numeric strings, are compared
+// as per compareJS, as they are by DefaultOps.
+func compareDecimals(l, r interface{}) (int, bool) {
+	lVal, lok := toDecimal(l)
+	rVal, rok := toDecimal(r)
+	if !lok || !rok {
+		return compareJS(l, r)
+	}
+	return lVal.Cmp(rVal), true
+}
+
+// buildDecimalCompareOp builds a comparison. As with DefaultOps, only <
+// and <=, for which between is true, treat three arguments as a between
+// test, l < m < r. Other comparisons ignore any arguments after the first
+// two.
+func buildDecimalCompareOp(between bool, cmp func(c int) bool) func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		if len(args) < 2 {
+			return falsef, nil
+		}
+
+		n := 2
+		if between && len(args) >= 3 {
+			n = 3
+		}
+		termArgs, err := buildTermArgs(args[:n], ops)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, data interface{}) interface{} {
+			var prev interface{}
+			for i, ta := range termArgs {
+				item := ta(ctx, data)
+				if i > 0 {
+					c, ok := compareDecimals(prev, item)
+					if !ok || !cmp(c) {
+						return false
+					}
+				}
+				prev = item
+			}
+			return true
+		}, nil
+	}
+}
+
+func buildDecimalMinMaxOp(better func(c int) bool) func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		if len(args) == 0 {
+			return nullf, nil
+		}
+
+		termArgs, err := buildTermArgs(args, ops)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, data interface{}) interface{} {
+			var resp *big.Rat
+			for _, ta := range termArgs {
+				item, ok := toDecimal(ta(ctx, data))
+				if !ok {
+					return math.NaN()
+				}
+				if resp == nil || better(item.Cmp(resp)) {
+					resp = item
+				}
+			}
+			return decimalResult(resp)
+		}, nil
+	}
+}
+
+// isDecimalSoftEqual compares numbers exactly, and defers to IsSoftEqual
+// for everything else.
+func isDecimalSoftEqual(l, r interface{}) bool {
+	switch {
+	case l == nil || r == nil:
+		return IsSoftEqual(l, r)
+	case !isNumber(l) && !isNumber(r):
+		return IsSoftEqual(l, r)
+	}
+
+	_, lisslice := l.([]interface{})
+	_, risslice := r.([]interface{})
+	_, lismap := l.(map[string]interface{})
+	_, rismap := r.(map[string]interface{})
+	if lisslice || risslice || lismap || rismap {
+		return IsSoftEqual(l, r)
+	}
+
+	lVal, lok := toDecimal(l)
+	rVal, rok := toDecimal(r)
+	return lok && rok && lVal.Cmp(rVal) == 0
+}
+
+func buildDecimalEqualOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	switch {
+	case len(args) == 0:
+		return truef, nil
+	case len(args) == 1:
+		return falsef, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		return isDecimalSoftEqual(lArg(ctx, data), rArg(ctx, data))
+	}, nil
+}
+
+func buildDecimalNotEqualOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	eqf, err := buildDecimalEqualOp(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		if eqres, ok := eqf(ctx, data).(bool); ok {
+			return !eqres
+		}
+		return false
+	}, nil
+}
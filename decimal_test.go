@@ -0,0 +1,311 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalResult(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"3/10", "0.3"},
+		{"-3/10", "-0.3"},
+		{"5/1", "5"},
+		{"0/1", "0"},
+		{"1/1024", "0.0009765625"},
+		{"1/3", "0.3333333333333333333333333333333333"},
+		{"2/3", "0.6666666666666666666666666666666667"},
+		{"-1/3", "-0.3333333333333333333333333333333333"},
+		{"1/100000000000000000000000000000000000000", "0.00000000000000000000000000000000000001"},
+		{"1/300000000000000000000000000000000000000", "0"},
+	}
+
+	for _, st := range tests {
+		t.Run(st.in, func(t *testing.T) {
+			r, ok := new(big.Rat).SetString(st.in)
+			assert.True(t, ok)
+			assert.Equal(t, json.Number(st.expect), decimalResult(r))
+		})
+	}
+}
+
+func TestDecimalOps(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   string
+		expect interface{}
+	}
+
+	tests := []test{
+		{
+			name:   "plus",
+			rule:   `{"+":[0.1,0.2]}`,
+			expect: json.Number("0.3"),
+		},
+		{
+			name:   "plus-equal",
+			rule:   `{"==":[{"+":[0.1,0.2]},0.3]}`,
+			expect: true,
+		},
+		{
+			name:   "plus-no-args",
+			rule:   `{"+":[]}`,
+			expect: json.Number("0"),
+		},
+		{
+			name:   "plus-string",
+			rule:   `{"+":["1.10"," 2.20 "]}`,
+			expect: json.Number("3.3"),
+		},
+		{
+			name:   "plus-nan",
+			rule:   `{"+":[1,"abc"]}`,
+			expect: math.NaN(),
+		},
+		{
+			name:   "minus",
+			rule:   `{"-":[10.1,0.3,0.8]}`,
+			expect: json.Number("9"),
+		},
+		{
+			name:   "unary-minus",
+			rule:   `{"-":[0.1]}`,
+			expect: json.Number("-0.1"),
+		},
+		{
+			name:   "multiply",
+			rule:   `{"*":[19.99,3]}`,
+			expect: json.Number("59.97"),
+		},
+		{
+			name:   "discount",
+			rule:   `{"*":[{"var":"price"},{"-":[1,{"var":"discount"}]}]}`,
+			data:   `{"price":19.99,"discount":0.15}`,
+			expect: json.Number("16.9915"),
+		},
+		{
+			name:   "divide",
+			rule:   `{"/":[1,8]}`,
+			expect: json.Number("0.125"),
+		},
+		{
+			name:   "divide-recurring",
+			rule:   `{"/":[10,3]}`,
+			expect: json.Number("3.3333333333333333333333333333333333"),
+		},
+		{
+			name:   "divide-zero",
+			rule:   `{"/":[1,0]}`,
+			expect: math.Inf(1),
+		},
+		{
+			name:   "modulo",
+			rule:   `{"%":[1.1,0.5]}`,
+			expect: json.Number("0.1"),
+		},
+		{
+			name:   "modulo-negative",
+			rule:   `{"%":[-7,3]}`,
+			expect: json.Number("-1"),
+		},
+		{
+			name:   "less",
+			rule:   `{"<":[{"+":[0.1,0.2]},0.3]}`,
+			expect: false,
+		},
+		{
+			name:   "less-equal",
+			rule:   `{"<=":[{"+":[0.1,0.2]},0.3]}`,
+			expect: true,
+		},
+		{
+			name:   "between",
+			rule:   `{"<":[0.1,{"+":[0.1,0.1]},0.3]}`,
+			expect: true,
+		},
+		{
+			name:   "between-inclusive",
+			rule:   `{"<=":[0.1,{"-":[0.4,0.1]},0.3]}`,
+			expect: true,
+		},
+		{
+			name:   "less-dates",
+			rule:   `{"<":["2020-01-01","2021-01-01"]}`,
+			expect: true,
+		},
+		{
+			name:   "less-strings",
+			rule:   `{"<":["a","b"]}`,
+			expect: true,
+		},
+		{
+			name:   "between-strings",
+			rule:   `{"<=":["a",{"var":"s"},"c"]}`,
+			data:   `{"s":"b"}`,
+			expect: true,
+		},
+		{
+			name:   "less-string-number",
+			rule:   `{"<":["a",1]}`,
+			expect: false,
+		},
+		{
+			name:   "greater",
+			rule:   `{">":[{"var":"a"},{"var":"b"}]}`,
+			data:   `{"a":"10000000000000000000000.1","b":10000000000000000000000}`,
+			expect: true,
+		},
+		{
+			name:   "greater-equal-nan",
+			rule:   `{">=":["abc",1]}`,
+			expect: false,
+		},
+		{
+			name:   "min",
+			rule:   `{"min":[0.3,{"+":[0.1,0.2]},"0.31"]}`,
+			expect: json.Number("0.3"),
+		},
+		{
+			name:   "max",
+			rule:   `{"max":[1,"2.5",2]}`,
+			expect: json.Number("2.5"),
+		},
+		{
+			name:   "not-equal",
+			rule:   `{"!=":[{"*":[1.1,1.1]},1.21]}`,
+			expect: false,
+		},
+		{
+			name:   "equal-strings",
+			rule:   `{"==":["0.30","0.3"]}`,
+			expect: false,
+		},
+		{
+			name:   "equal-coerced",
+			rule:   `{"==":[{"+":[0.1,0.2]},"0.30"]}`,
+			expect: true,
+		},
+		{
+			name:   "equal-nil",
+			rule:   `{"==":[{"var":"missing"},0]}`,
+			expect: false,
+		},
+		{
+			name:   "json-number-data",
+			rule:   `{"+":[{"var":"a"},{"var":"b"}]}`,
+			data:   `{"a":0.1,"b":0.2}`,
+			expect: json.Number("0.3"),
+		},
+		{
+			name:   "truthy-result",
+			rule:   `{"if":[{"-":[0.3,0.1,0.2]},"yes","no"]}`,
+			expect: "no",
+		},
+		{
+			name:   "cat-result",
+			rule:   `{"cat":["total: ",{"+":[0.1,0.2]}]}`,
+			expect: "total: 0.3",
+		},
+		{
+			name:   "reduce",
+			rule:   `{"reduce":[{"var":"prices"},{"+":[{"var":"accumulator"},{"var":"current"}]},0]}`,
+			data:   `{"prices":[0.1,0.1,0.1,0.1,0.1,0.1,0.1,0.1,0.1,0.1]}`,
+			expect: json.Number("1"),
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				var data interface{}
+				if st.data != "" {
+					dec := json.NewDecoder(bytes.NewBufferString(st.data))
+					dec.UseNumber()
+					err = dec.Decode(&data)
+					assert.NoErrorf(t, err, "data unmarshal error")
+				}
+
+				cf, err := DecimalOps.Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), data)
+				if f, ok := st.expect.(float64); ok && math.IsNaN(f) {
+					vf, ok := v.(float64)
+					assert.Truef(t, ok && math.IsNaN(vf), "expected NaN, got %v", v)
+					return
+				}
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
+
+func TestDecimalOpsDefaultUnchanged(t *testing.T) {
+	var c Clause
+	err := json.Unmarshal([]byte(`{"+":[0.1,0.2]}`), &c)
+	assert.NoError(t, err)
+
+	cf, err := Compile(&c)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.30000000000000004, cf(context.Background(), nil))
+}
+
+func TestDecimalOpsCompareLikeDefault(t *testing.T) {
+	rules := []string{
+		`{">":[3,2,5]}`,
+		`{">=":[3,3,5]}`,
+		`{">":[1,2,0]}`,
+		`{">=":[3,2,1,{"nope":[]}]}`,
+		`{"<":[1,2,3]}`,
+		`{"<":[1,3,2]}`,
+		`{"<=":[1,1,1,0]}`,
+	}
+
+	ctx := context.Background()
+	for _, rule := range rules {
+		t.Run(rule, func(t *testing.T) {
+			var c Clause
+			err := json.Unmarshal([]byte(rule), &c)
+			assert.NoErrorf(t, err, "unmarshal error")
+
+			cf, err := Compile(&c)
+			assert.NoError(t, err)
+			dcf, err := DecimalOps.Compile(&c)
+			if assert.NoError(t, err) {
+				assert.Equal(t, cf(ctx, nil), dcf(ctx, nil))
+			}
+		})
+	}
+}
+
+func TestDecimalOpsExactLiterals(t *testing.T) {
+	const rule = `{"+":[12345678901234567890.12,0]}`
+
+	var c Clause
+	err := UnmarshalNumbers([]byte(rule), &c)
+	assert.NoError(t, err)
+	cf, err := DecimalOps.Compile(&c)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("12345678901234567890.12"), cf(context.Background(), nil))
+
+	// float64 literals can not hold the value exactly.
+	var fc Clause
+	err = json.Unmarshal([]byte(rule), &fc)
+	assert.NoError(t, err)
+	cf, err = DecimalOps.Compile(&fc)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("12345678901234567000"), cf(context.Background(), nil))
+}
@@ -0,0 +1,547 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// HitPolicy selects which of the matching rows of a decision table give
+// its result.
+type HitPolicy string
+
+const (
+	// HitUnique requires at most one row to match any input, so rows must
+	// not overlap.
+	HitUnique HitPolicy = "unique"
+	// HitFirst uses the first matching row.
+	HitFirst HitPolicy = "first"
+	// HitPriority uses the matching row with the highest priority, rows
+	// with the same priority must not overlap.
+	HitPriority HitPolicy = "priority"
+	// HitCollect uses every matching row, in order.
+	HitCollect HitPolicy = "collect"
+)
+
+// DecisionTableSpec is a decision table, as written in JSON, for example
+//
+//	{
+//	  "hit_policy": "first",
+//	  "inputs": [{"name": "age", "expr": {"var": "age"}}, {"name": "country", "expr": {"var": "country"}}],
+//	  "outputs": ["discount"],
+//	  "rows": [
+//	    {"when": [{">=": [65]}, "-"], "then": [0.2]},
+//	    {"when": [{"<": [18]}, ["GB", "IE"]], "then": [0.1]},
+//	    {"when": ["-", "-"], "then": [0]}
+//	  ]
+//	}
+//
+// Each cell of a row is a condition on the value of its input, and is
+// evaluated with that value as the data. A cell may be "-", which matches
+// anything, a literal, which must equal the value, or an array literal,
+// which must contain it. Otherwise, the cell is an operation with the value
+// inserted as its first argument, so {">=": [65]} is value >= 65, other than
+// {"<": [a, b]} and {"<=": [a, b]}, which test the value is between a and b.
+// The arguments of !, and and or are themselves cells.
+//
+// Output cells are evaluated against the data.
+type DecisionTableSpec struct {
+	HitPolicy HitPolicy       `json:"hit_policy"`
+	Inputs    []DecisionInput `json:"inputs"`
+	Outputs   []string        `json:"outputs"`
+	Rows      []DecisionRow   `json:"rows"`
+}
+
+// DecisionInput is an input column of a decision table.
+type DecisionInput struct {
+	Name string  `json:"name"`
+	Expr *Clause `json:"expr"`
+}
+
+// DecisionRow is a row of a decision table, with a cell for each input
+// and output.
+type DecisionRow struct {
+	When     []*Clause `json:"when"`
+	Then     []*Clause `json:"then"`
+	Priority int       `json:"priority,omitempty"`
+}
+
+// DecisionResult is a row of a decision table that matched.
+type DecisionResult struct {
+	// Row is the index of the row in the table.
+	Row     int
+	Outputs map[string]interface{}
+}
+
+// DecisionTableError is the error returned by NewDecisionTable for tables
+// with overlapping or unreachable rows.
+type DecisionTableError struct {
+	Issues []string
+}
+
+func (e *DecisionTableError) Error() string {
+	return "invalid decision table, " + strings.Join(e.Issues, ", ")
+}
+
+// DecisionTable is a compiled decision table. A DecisionTable is safe for
+// concurrent use.
+type DecisionTable struct {
+	policy  HitPolicy
+	inputs  []ClauseFunc
+	outputs []string
+	rows    []decisionRow
+}
+
+type decisionRow struct {
+	index    int
+	priority int
+	// cells holds the condition for each input, nil for any value.
+	cells   []ClauseFunc
+	outputs []ClauseFunc
+}
+
+// matches reports whether the row matches the input values.
+func (r decisionRow) matches(ctx context.Context, vals []interface{}) bool {
+	for i, cell := range r.cells {
+		if cell != nil && !IsTrue(cell(ctx, vals[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAnyCell reports whether a cell matches any value.
+func isAnyCell(c *Clause) bool {
+	v, ok := constantArg(Argument{Clause: c})
+	return ok && v == "-"
+}
+
+// expandCell returns the condition for a cell, as a clause on the value,
+// and whether it is one whose candidate values can be used to decide
+// whether it covers another cell.
+func expandCell(arg Argument) (Argument, bool) {
+	value := varArg("")
+	if v, ok := constantArg(arg); ok {
+		if arr, ok := v.([]interface{}); ok {
+			return opArg(inOp, value, Argument{Value: v}), isScalarArray(arr)
+		}
+		return opArg(equalOp, value, Argument{Value: v}), isScalar(v)
+	}
+
+	op, args := arg.Clause.Operator.Name, arg.Clause.Arguments
+	switch {
+	case op == negateOp && len(args) == 1:
+		e, analyzable := expandCell(args[0])
+		return opArg(negateOp, e), analyzable
+	case (op == andOp || op == orOp) && len(args) != 0:
+		analyzable := true
+		es := make([]Argument, len(args))
+		for i, a := range args {
+			var ok bool
+			es[i], ok = expandCell(a)
+			analyzable = analyzable && ok
+		}
+		return opArg(op, es...), analyzable
+	case (op == lessOp || op == lessEqOp) && len(args) == 2:
+		// A between test, a < value < b
+		lower, lok := constantArg(args[0])
+		upper, uok := constantArg(args[1])
+		analyzable := lok && uok && isNumber(lower) && isNumber(upper)
+		return opArg(op, args[0], value, args[1]), analyzable
+	}
+
+	analyzable := false
+	if len(args) == 1 {
+		v, ok := constantArg(args[0])
+		switch op {
+		case equalOp, equalThreeOp, notEqualOp, notEqualThreeOp:
+			analyzable = ok && isScalar(v)
+		case lessOp, lessEqOp, greaterOp, greaterEqOp:
+			analyzable = ok && isNumber(v)
+		case inOp:
+			arr, isarr := v.([]interface{})
+			analyzable = ok && isarr && isScalarArray(arr)
+		}
+	}
+	return opArg(op, append([]Argument{value}, args...)...), analyzable
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	}
+	return true
+}
+
+func isScalarArray(arr []interface{}) bool {
+	for _, v := range arr {
+		if !isScalar(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellLiterals appends the literals in a cell to lits.
+func cellLiterals(arg Argument, lits []interface{}) []interface{} {
+	if v, ok := constantArg(arg); ok {
+		if arr, ok := v.([]interface{}); ok {
+			return append(lits, arr...)
+		}
+		return append(lits, v)
+	}
+	for _, a := range arg.Clause.Arguments {
+		lits = cellLiterals(a, lits)
+	}
+	return lits
+}
+
+// candidateValues returns values to test the cells of a column with. It
+// holds the literals of the cells, numbers either side of and between the
+// numeric literals, and values of each type.
+func candidateValues(lits []interface{}) []interface{} {
+	cands := []interface{}{nil, true, false, "", 0.0}
+	var nums []float64
+	for _, l := range lits {
+		cands = append(cands, l)
+		if isNumber(l) {
+			nums = append(nums, toNumber(l))
+		}
+	}
+	sort.Float64s(nums)
+	for i, n := range nums {
+		cands = append(cands, n-1, n-0.5, n+0.5, n+1)
+		if i > 0 && nums[i-1] != n {
+			cands = append(cands, (nums[i-1]+n)/2)
+		}
+	}
+	return cands
+}
+
+// NewDecisionTable compiles a decision table, with ops, or DefaultOps if
+// ops is nil. Rows that can never match, rows that are unreachable under
+// the hit policy, as an earlier or higher priority row always matches
+// first, and overlapping rows, under the unique and priority hit policies,
+// are reported in a *DecisionTableError.
+//
+// These checks are a best effort lint, not a proof. Overlapping rows are
+// found by testing the cells with candidate values, derived from the
+// literals in them, assuming the inputs are independent. So overlaps only
+// found by values that are not candidates, such as those matched by
+// custom operations, are missed, and overlaps that correlated inputs
+// never produce are reported. Rows are only reported unreachable if the
+// cells involved are "-", literals, or comparisons with literals, possibly
+// combined with !, and and or. Every issue found is reported, so a row
+// covered by several earlier rows is reported once for each.
+func NewDecisionTable(spec DecisionTableSpec, ops OpsSet) (*DecisionTable, error) {
+	if ops == nil {
+		ops = DefaultOps
+	}
+	switch spec.HitPolicy {
+	case HitUnique, HitFirst, HitPriority, HitCollect:
+	default:
+		return nil, fmt.Errorf("unknown hit policy %q", spec.HitPolicy)
+	}
+
+	t := &DecisionTable{policy: spec.HitPolicy, outputs: spec.Outputs}
+	for _, in := range spec.Inputs {
+		if in.Expr == nil {
+			return nil, fmt.Errorf("input %q has no expression", in.Name)
+		}
+		cf, err := ops.Compile(in.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("could not compile input %q, %w", in.Name, err)
+		}
+		t.inputs = append(t.inputs, cf)
+	}
+	outputs := map[string]bool{}
+	for _, out := range spec.Outputs {
+		if outputs[out] {
+			return nil, fmt.Errorf("duplicate output %q", out)
+		}
+		outputs[out] = true
+	}
+
+	analyzable := make([][]bool, len(spec.Rows))
+	lits := make([][]interface{}, len(spec.Inputs))
+	for i, row := range spec.Rows {
+		if len(row.When) != len(spec.Inputs) || len(row.Then) != len(spec.Outputs) {
+			return nil, fmt.Errorf("row %d has %d input and %d output cells, rather than %d and %d",
+				i, len(row.When), len(row.Then), len(spec.Inputs), len(spec.Outputs))
+		}
+
+		r := decisionRow{index: i, priority: row.Priority}
+		analyzable[i] = make([]bool, len(row.When))
+		for j, cell := range row.When {
+			if cell == nil || isAnyCell(cell) {
+				r.cells = append(r.cells, nil)
+				analyzable[i][j] = true
+				continue
+			}
+			e, ok := expandCell(Argument{Clause: cell})
+			cf, err := BuildArgFunc(e, ops)
+			if err != nil {
+				return nil, fmt.Errorf("could not compile row %d input %q, %w", i, spec.Inputs[j].Name, err)
+			}
+			r.cells = append(r.cells, cf)
+			analyzable[i][j] = ok
+			lits[j] = cellLiterals(Argument{Clause: cell}, lits[j])
+		}
+		for j, cell := range row.Then {
+			cf := nullf
+			if cell != nil {
+				var err error
+				if cf, err = ops.Compile(cell); err != nil {
+					return nil, fmt.Errorf("could not compile row %d output %q, %w", i, spec.Outputs[j], err)
+				}
+			}
+			r.outputs = append(r.outputs, cf)
+		}
+		t.rows = append(t.rows, r)
+	}
+
+	cands := make([][]interface{}, len(lits))
+	for j := range lits {
+		cands[j] = candidateValues(lits[j])
+	}
+	if issues := t.check(cands, analyzable); len(issues) != 0 {
+		return nil, &DecisionTableError{Issues: issues}
+	}
+
+	if t.policy == HitPriority {
+		sort.SliceStable(t.rows, func(i, j int) bool {
+			return t.rows[i].priority > t.rows[j].priority
+		})
+	}
+	return t, nil
+}
+
+// check returns the issues with the rows, testing the cells with the
+// candidate values for each input.
+func (t *DecisionTable) check(cands [][]interface{}, analyzable [][]bool) []string {
+	ctx := context.Background()
+	cellMatches := func(cell ClauseFunc, v interface{}) bool {
+		return cell == nil || IsTrue(cell(ctx, v))
+	}
+
+	// overlap reports whether some value of each input matches both rows.
+	overlap := func(a, b decisionRow) bool {
+		for j := range t.inputs {
+			found := false
+			for _, v := range cands[j] {
+				if cellMatches(a.cells[j], v) && cellMatches(b.cells[j], v) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	// covers reports whether a matches whenever b does.
+	covers := func(a, b decisionRow) bool {
+		for j := range t.inputs {
+			if !analyzable[a.index][j] || !analyzable[b.index][j] {
+				return false
+			}
+			for _, v := range cands[j] {
+				if cellMatches(b.cells[j], v) && !cellMatches(a.cells[j], v) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	// satisfiable reports whether, for each input with an analyzable cell,
+	// some value matches it.
+	satisfiable := func(b decisionRow) bool {
+		for j := range t.inputs {
+			if !analyzable[b.index][j] {
+				continue
+			}
+			found := false
+			for _, v := range cands[j] {
+				if cellMatches(b.cells[j], v) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	var issues []string
+	for _, b := range t.rows {
+		if !satisfiable(b) {
+			issues = append(issues, fmt.Sprintf("row %d can never match", b.index))
+			continue
+		}
+		for _, a := range t.rows {
+			if a.index == b.index {
+				continue
+			}
+			before := a.index < b.index
+			switch {
+			case t.policy == HitUnique && before && overlap(a, b):
+				issues = append(issues, fmt.Sprintf("rows %d and %d overlap", a.index, b.index))
+			case t.policy == HitPriority && before && a.priority == b.priority && overlap(a, b):
+				issues = append(issues, fmt.Sprintf("rows %d and %d overlap with the same priority", a.index, b.index))
+			case t.policy == HitFirst && before && covers(a, b):
+				issues = append(issues, fmt.Sprintf("row %d is unreachable, as row %d matches first", b.index, a.index))
+			case t.policy == HitPriority && a.priority > b.priority && covers(a, b):
+				issues = append(issues, fmt.Sprintf("row %d is unreachable, as row %d has a higher priority", b.index, a.index))
+			}
+		}
+	}
+	return issues
+}
+
+// Evaluate evaluates the table against data, returning the matching rows
+// selected by the hit policy. An error is returned if more than one row
+// matches a table with the unique hit policy.
+func (t *DecisionTable) Evaluate(ctx context.Context, data interface{}) ([]DecisionResult, error) {
+	vals := make([]interface{}, len(t.inputs))
+	for i, in := range t.inputs {
+		vals[i] = in(ctx, data)
+	}
+
+	var results []DecisionResult
+	for _, r := range t.rows {
+		if !r.matches(ctx, vals) {
+			continue
+		}
+		if t.policy == HitUnique && len(results) != 0 {
+			return nil, fmt.Errorf("rows %d and %d both match, violating the unique hit policy", results[0].Row, r.index)
+		}
+
+		outputs := make(map[string]interface{}, len(t.outputs))
+		for i, out := range r.outputs {
+			outputs[t.outputs[i]] = out(ctx, data)
+		}
+		results = append(results, DecisionResult{Row: r.index, Outputs: outputs})
+		if t.policy == HitFirst || t.policy == HitPriority {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ReadDecisionTableCSV reads a decision table from CSV. The first record
+// is a header naming the columns. Input columns are named by a var path,
+// or the input expression as JSON, output columns by their name prefixed
+// with "=>", and an optional column named "priority" holds the row
+// priorities. For example
+//
+//	age,country,=>discount
+//	>= 65,-,0.2
+//	< 18,"[""GB"",""IE""]",0.1
+//	-,-,0
+//
+// Empty input cells match anything, as "-" does. Cells starting with a
+// comparison operation, or "in ", are that operation on the literal that
+// follows. Otherwise cells are JSON, or if they are not valid JSON, a
+// string.
+func ReadDecisionTableCSV(r io.Reader, policy HitPolicy) (DecisionTableSpec, error) {
+	spec := DecisionTableSpec{HitPolicy: policy}
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return spec, err
+	}
+	if len(records) == 0 {
+		return spec, fmt.Errorf("no header in decision table")
+	}
+
+	// kinds holds, for each column, "in", "out" or "priority".
+	header := records[0]
+	kinds := make([]string, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		switch {
+		case h == "priority":
+			kinds[i] = "priority"
+		case strings.HasPrefix(h, "=>"):
+			kinds[i] = "out"
+			spec.Outputs = append(spec.Outputs, strings.TrimSpace(h[2:]))
+		default:
+			kinds[i] = "in"
+			expr := varArg(h).Clause
+			if strings.HasPrefix(h, "{") {
+				expr = &Clause{}
+				if err := json.Unmarshal([]byte(h), expr); err != nil {
+					return spec, fmt.Errorf("could not parse input %q, %w", h, err)
+				}
+			}
+			spec.Inputs = append(spec.Inputs, DecisionInput{Name: h, Expr: expr})
+		}
+	}
+
+	for n, rec := range records[1:] {
+		var row DecisionRow
+		for i, cell := range rec {
+			cell = strings.TrimSpace(cell)
+			switch kinds[i] {
+			case "priority":
+				if cell == "" {
+					continue
+				}
+				var p float64
+				if err := json.Unmarshal([]byte(cell), &p); err != nil || p != math.Trunc(p) {
+					return spec, fmt.Errorf("row %d has priority %q, rather than an integer", n, cell)
+				}
+				row.Priority = int(p)
+			case "out":
+				if cell == "" {
+					row.Then = append(row.Then, nil)
+					continue
+				}
+				row.Then = append(row.Then, csvCellClause(cell))
+			default:
+				if cell == "" {
+					cell = "-"
+				}
+				row.When = append(row.When, csvInputCell(cell))
+			}
+		}
+		spec.Rows = append(spec.Rows, row)
+	}
+	return spec, nil
+}
+
+// csvCellOps holds the operations a CSV input cell may start with, with
+// longer operations before their prefixes.
+var csvCellOps = []string{
+	equalThreeOp, notEqualThreeOp, equalOp, notEqualOp,
+	lessEqOp, greaterEqOp, lessOp, greaterOp, inOp + " ",
+}
+
+func csvInputCell(cell string) *Clause {
+	for _, op := range csvCellOps {
+		if strings.HasPrefix(cell, op) {
+			arg := csvCellClause(strings.TrimSpace(cell[len(op):]))
+			return opArg(strings.TrimSpace(op), Argument{Clause: arg}).Clause
+		}
+	}
+	return csvCellClause(cell)
+}
+
+// csvCellClause parses a cell as JSON, or if it is not valid JSON, as a
+// string.
+func csvCellClause(cell string) *Clause {
+	c := &Clause{}
+	if err := json.Unmarshal([]byte(cell), c); err != nil {
+		return &Clause{Arguments: Arguments{{Value: cell}}}
+	}
+	return c
+}
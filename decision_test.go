@@ -0,0 +1,331 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDecisionInputs = `[{"name": "age", "expr": {"var": "age"}}, {"name": "country", "expr": {"var": "country"}}]`
+
+func TestDecisionTableEvaluate(t *testing.T) {
+	type test struct {
+		name   string
+		table  string
+		data   string
+		expect []DecisionResult
+		err    string
+	}
+
+	tests := []test{
+		{
+			name: "first",
+			table: `{"hit_policy": "first", "inputs": ` + testDecisionInputs + `, "outputs": ["discount"], "rows": [
+				{"when": [{">=": [65]}, "-"], "then": [0.2]},
+				{"when": [{"<": [18]}, ["GB", "IE"]], "then": [0.1]},
+				{"when": ["-", "-"], "then": [0]}
+			]}`,
+			data:   `{"age": 12, "country": "IE"}`,
+			expect: []DecisionResult{{Row: 1, Outputs: map[string]interface{}{"discount": 0.1}}},
+		},
+		{
+			name: "first-fallthrough",
+			table: `{"hit_policy": "first", "inputs": ` + testDecisionInputs + `, "outputs": ["discount"], "rows": [
+				{"when": [{">=": [65]}, "-"], "then": [0.2]},
+				{"when": [{"<": [18]}, ["GB", "IE"]], "then": [0.1]},
+				{"when": ["-", "-"], "then": [0]}
+			]}`,
+			data:   `{"age": 12, "country": "FR"}`,
+			expect: []DecisionResult{{Row: 2, Outputs: map[string]interface{}{"discount": 0.0}}},
+		},
+		{
+			name: "unique",
+			table: `{"hit_policy": "unique", "inputs": ` + testDecisionInputs + `, "outputs": ["band", "price"], "rows": [
+				{"when": [{"<": [18]}, "-"], "then": ["child", {"*": [{"var": "base"}, 0.5]}]},
+				{"when": [{"<=": [18, 65]}, "-"], "then": ["adult", {"var": "base"}]},
+				{"when": [{">": [65]}, {"!": "GB"}], "then": ["senior", null]},
+				{"when": [{">": [65]}, "GB"], "then": ["senior", 0]}
+			]}`,
+			data:   `{"age": 17, "base": 10}`,
+			expect: []DecisionResult{{Row: 0, Outputs: map[string]interface{}{"band": "child", "price": 5.0}}},
+		},
+		{
+			name: "unique-none",
+			table: `{"hit_policy": "unique", "inputs": ` + testDecisionInputs + `, "outputs": ["band"], "rows": [
+				{"when": [{"<": [18]}, "-"], "then": ["child"]},
+				{"when": [{">": [65]}, "-"], "then": ["senior"]}
+			]}`,
+			data: `{"age": 30}`,
+		},
+		{
+			name: "priority",
+			table: `{"hit_policy": "priority", "inputs": ` + testDecisionInputs + `, "outputs": ["offer"], "rows": [
+				{"when": ["-", "-"], "then": ["none"]},
+				{"when": [{"or": [{"<": [18]}, {">": [65]}]}, "-"], "then": ["concession"], "priority": 1},
+				{"when": ["-", {"in": [["GB", "IE"]]}], "then": ["local"], "priority": 2}
+			]}`,
+			data:   `{"age": 70, "country": "FR"}`,
+			expect: []DecisionResult{{Row: 1, Outputs: map[string]interface{}{"offer": "concession"}}},
+		},
+		{
+			name: "collect",
+			table: `{"hit_policy": "collect", "inputs": ` + testDecisionInputs + `, "outputs": ["tag"], "rows": [
+				{"when": [{">=": [18]}, "-"], "then": ["adult"]},
+				{"when": ["-", "GB"], "then": ["uk"]},
+				{"when": [{"<": [0]}, "-"], "then": ["invalid"]},
+				{"when": ["-", "-"], "then": ["all"]}
+			]}`,
+			data: `{"age": 30, "country": "GB"}`,
+			expect: []DecisionResult{
+				{Row: 0, Outputs: map[string]interface{}{"tag": "adult"}},
+				{Row: 1, Outputs: map[string]interface{}{"tag": "uk"}},
+				{Row: 3, Outputs: map[string]interface{}{"tag": "all"}},
+			},
+		},
+		{
+			name: "unique-violated",
+			table: `{"hit_policy": "unique", "inputs": [{"name": "name", "expr": {"var": "name"}}], "outputs": ["x"], "rows": [
+				{"when": [{"long": []}], "then": [1]},
+				{"when": [{"!": "ab"}], "then": [2]}
+			]}`,
+			data: `{"name": "abcdef"}`,
+			err:  `rows 0 and 1 both match, violating the unique hit policy`,
+		},
+	}
+
+	ops := OpsSet{}
+	for k, v := range DefaultOps {
+		ops[k] = v
+	}
+	ops["long"] = func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		return func(ctx context.Context, data interface{}) interface{} {
+			return len(toString(data)) > 5
+		}, nil
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var spec DecisionTableSpec
+				err := json.Unmarshal([]byte(st.table), &spec)
+				assert.NoErrorf(t, err, "unmarshal error")
+				var data interface{}
+				err = json.Unmarshal([]byte(st.data), &data)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				dt, err := NewDecisionTable(spec, ops)
+				if !assert.NoError(t, err) {
+					return
+				}
+				res, err := dt.Evaluate(context.Background(), data)
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, st.expect, res)
+			})
+		})
+	}
+}
+
+func TestNewDecisionTable(t *testing.T) {
+	type test struct {
+		name  string
+		table string
+		err   string
+	}
+
+	tests := []test{
+		{
+			name:  "unknown-policy",
+			table: `{"hit_policy": "any", "inputs": ` + testDecisionInputs + `}`,
+			err:   `unknown hit policy "any"`,
+		},
+		{
+			name:  "no-input-expression",
+			table: `{"hit_policy": "first", "inputs": [{"name": "age"}]}`,
+			err:   `input "age" has no expression`,
+		},
+		{
+			name:  "duplicate-output",
+			table: `{"hit_policy": "first", "outputs": ["a", "a"]}`,
+			err:   `duplicate output "a"`,
+		},
+		{
+			name: "cell-count",
+			table: `{"hit_policy": "first", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": ["-"], "then": [1]}
+			]}`,
+			err: `row 0 has 1 input and 1 output cells, rather than 2 and 1`,
+		},
+		{
+			name: "compile-error",
+			table: `{"hit_policy": "first", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": ["-", {"nope": []}], "then": [1]}
+			]}`,
+			err: `could not compile row 0 input "country", unrecognized operation nope`,
+		},
+		{
+			name: "unique-overlap",
+			table: `{"hit_policy": "unique", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": [{"<": [18]}, "GB"], "then": [1]},
+				{"when": [{"<=": [18, 65]}, "GB"], "then": [2]},
+				{"when": [{"<": [20]}, ["FR", "GB"]], "then": [3]},
+				{"when": [{">": [65]}, {"!": "GB"}], "then": [4]}
+			]}`,
+			err: `invalid decision table, rows 0 and 2 overlap, rows 1 and 2 overlap`,
+		},
+		{
+			name: "first-unreachable",
+			table: `{"hit_policy": "first", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": [{">=": [18]}, "-"], "then": [1]},
+				{"when": [{"<=": [20, 30]}, "GB"], "then": [2]},
+				{"when": [{"<": [18]}, {"in": [["GB", "IE"]]}], "then": [3]},
+				{"when": [{"<": [17]}, "IE"], "then": [4]},
+				{"when": [{"cat": []}, "IE"], "then": [5]},
+				{"when": [{"<": [30, 20]}, "-"], "then": [6]}
+			]}`,
+			err: `invalid decision table, row 1 is unreachable, as row 0 matches first, ` +
+				`row 3 is unreachable, as row 2 matches first, row 5 can never match`,
+		},
+		{
+			name: "first-unreachable-several",
+			table: `{"hit_policy": "first", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": [{">=": [18]}, "-"], "then": [1]},
+				{"when": ["-", "GB"], "then": [2]},
+				{"when": [{">": [30]}, "GB"], "then": [3]}
+			]}`,
+			err: `invalid decision table, row 2 is unreachable, as row 0 matches first, ` +
+				`row 2 is unreachable, as row 1 matches first`,
+		},
+		{
+			name: "priority-several",
+			table: `{"hit_policy": "priority", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": ["-", "GB"], "then": [1], "priority": 2},
+				{"when": [{">": [18]}, "-"], "then": [2], "priority": 1},
+				{"when": [{">": [30]}, "GB"], "then": [3], "priority": 1}
+			]}`,
+			err: `invalid decision table, row 2 is unreachable, as row 0 has a higher priority, ` +
+				`rows 1 and 2 overlap with the same priority`,
+		},
+		{
+			name: "priority",
+			table: `{"hit_policy": "priority", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": ["-", "IE"], "then": [1]},
+				{"when": ["-", "GB"], "then": [2]},
+				{"when": [{">": [18]}, "-"], "then": [3], "priority": 1},
+				{"when": [{"<": [18]}, "GB"], "then": [4], "priority": 1},
+				{"when": ["-", "-"], "then": [5], "priority": 2}
+			]}`,
+			err: `invalid decision table, row 0 is unreachable, as row 4 has a higher priority, ` +
+				`row 1 is unreachable, as row 4 has a higher priority, row 2 is unreachable, as row 4 has a higher priority, ` +
+				`row 3 is unreachable, as row 4 has a higher priority`,
+		},
+		{
+			name: "priority-overlap",
+			table: `{"hit_policy": "priority", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": [{">": [18]}, "-"], "then": [3], "priority": 1},
+				{"when": [{"<": [19]}, "GB"], "then": [4], "priority": 1},
+				{"when": [{"<": [18]}, "IE"], "then": [5]}
+			]}`,
+			err: `invalid decision table, rows 0 and 1 overlap with the same priority`,
+		},
+		{
+			name: "collect-overlap",
+			table: `{"hit_policy": "collect", "inputs": ` + testDecisionInputs + `, "outputs": ["a"], "rows": [
+				{"when": ["-", "-"], "then": [1]},
+				{"when": ["-", "GB"], "then": [2]}
+			]}`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var spec DecisionTableSpec
+				err := json.Unmarshal([]byte(st.table), &spec)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				_, err = NewDecisionTable(spec, nil)
+				if st.err == "" {
+					assert.NoError(t, err)
+					return
+				}
+				assert.EqualError(t, err, st.err)
+				var dterr *DecisionTableError
+				if strings.HasPrefix(st.err, "invalid decision table") {
+					assert.True(t, errors.As(err, &dterr))
+				}
+			})
+		})
+	}
+}
+
+func TestReadDecisionTableCSV(t *testing.T) {
+	const table = `age,country,"{""cat"":[{""var"":""tier""},""!""]}",priority,=> discount,=> note
+>= 65,-,-,1,0.2,senior
+< 18,"[""GB"",""IE""]",,1,0.1,
+-,!= FR,gold!,2,0.3,"{""cat"":[""gold "",{""var"":""country""}]}"
+-,-,-,,0,
+`
+	spec, err := ReadDecisionTableCSV(strings.NewReader(table), HitPriority)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"discount", "note"}, spec.Outputs)
+	assert.Len(t, spec.Rows, 4)
+
+	dt, err := NewDecisionTable(spec, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	type test struct {
+		name   string
+		data   string
+		expect []DecisionResult
+	}
+
+	tests := []test{
+		{
+			name:   "senior",
+			data:   `{"age": 70, "country": "FR"}`,
+			expect: []DecisionResult{{Row: 0, Outputs: map[string]interface{}{"discount": 0.2, "note": "senior"}}},
+		},
+		{
+			name:   "gold",
+			data:   `{"age": 70, "country": "GB", "tier": "gold"}`,
+			expect: []DecisionResult{{Row: 2, Outputs: map[string]interface{}{"discount": 0.3, "note": "gold GB"}}},
+		},
+		{
+			name:   "child",
+			data:   `{"age": 10, "country": "IE"}`,
+			expect: []DecisionResult{{Row: 1, Outputs: map[string]interface{}{"discount": 0.1, "note": nil}}},
+		},
+		{
+			name:   "default",
+			data:   `{"age": 30, "country": "IE", "tier": "silver"}`,
+			expect: []DecisionResult{{Row: 3, Outputs: map[string]interface{}{"discount": 0.0, "note": nil}}},
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var data interface{}
+				err := json.Unmarshal([]byte(st.data), &data)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				res, err := dt.Evaluate(context.Background(), data)
+				assert.NoError(t, err)
+				assert.Equal(t, st.expect, res)
+			})
+		})
+	}
+
+	_, err = ReadDecisionTableCSV(strings.NewReader("priority,=>a\nhigh,1\n"), HitPriority)
+	assert.EqualError(t, err, `row 0 has priority "high", rather than an integer`)
+}
@@ -0,0 +1,209 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	// Inserted is an argument, or array element, only in the new rule.
+	Inserted ChangeKind = iota
+	// Deleted is an argument, or array element, only in the old rule.
+	Deleted
+	// Modified is a value, or operation, replaced in the new rule.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Inserted:
+		return "inserted"
+	case Deleted:
+		return "deleted"
+	case Modified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// Change is a difference between two rules, found by Diff.
+type Change struct {
+	Kind ChangeKind
+	// Path addresses the changed part of the rule, as the operations and
+	// argument indexes leading to it, so and[1].>[1] is the second argument
+	// of the > operation that is the second argument of the and. Elements
+	// of arrays are addressed the same way, so in[1][0] is the first element
+	// of an array that is the second argument of an in operation. Deleted
+	// arguments are addressed by their index in the old rule, others by
+	// their index in the new rule. The path of the whole rule is empty.
+	Path string
+	// Old and New hold the old and new values, or, for operations, their
+	// *Clause. Old is nil for insertions, and New is nil for deletions.
+	Old, New interface{}
+}
+
+// String renders the change, such as
+//
+//	and[1].>[1]: 18 → 21
+func (c Change) String() string {
+	var desc string
+	switch c.Kind {
+	case Inserted:
+		desc = "+ " + formatDiffValue(c.New)
+	case Deleted:
+		desc = "- " + formatDiffValue(c.Old)
+	default:
+		desc = formatDiffValue(c.Old) + " → " + formatDiffValue(c.New)
+	}
+	if c.Path == "" {
+		return desc
+	}
+	return c.Path + ": " + desc
+}
+
+func formatDiffValue(v interface{}) string {
+	bs, err := marshalUnescaped(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bs)
+}
+
+// FormatChanges renders changes, one per line.
+func FormatChanges(changes []Change) string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff returns the changes that turn rule a into rule b. Arguments are
+// compared as by Clause.Equal, so differences in how the rules were
+// written are ignored. Arguments inserted into, or deleted from, an
+// operation, or a literal array, are reported as such, rather than as
+// changes to the arguments that follow them.
+func Diff(a, b *Clause) []Change {
+	var changes []Change
+	diffArgs("", Argument{Clause: a}, Argument{Clause: b}, &changes)
+	return changes
+}
+
+func canonicalArg(a Argument) []byte {
+	buf := &bytes.Buffer{}
+	if a.Clause != nil {
+		writeCanonicalClause(buf, a.Clause)
+	} else {
+		writeCanonicalValue(buf, a.Value)
+	}
+	return buf.Bytes()
+}
+
+// diffValue returns the value of an argument, to report in a Change.
+func diffValue(a Argument) interface{} {
+	if v, ok := constantArg(a); ok {
+		return v
+	}
+	return a.Clause
+}
+
+func diffArgs(path string, a, b Argument, changes *[]Change) {
+	if bytes.Equal(canonicalArg(a), canonicalArg(b)) {
+		return
+	}
+
+	av, aok := constantArg(a)
+	bv, bok := constantArg(b)
+	switch {
+	case aok && bok:
+		aarr, aisarr := av.([]interface{})
+		barr, bisarr := bv.([]interface{})
+		if aisarr && bisarr {
+			diffLists(path, valueArgs(aarr), valueArgs(barr), changes)
+			return
+		}
+	case !aok && !bok && a.Clause.Operator.Name == b.Clause.Operator.Name:
+		op := a.Clause.Operator.Name
+		if path != "" && op != "" {
+			path += "."
+		}
+		diffLists(path+op, a.Clause.Arguments, b.Clause.Arguments, changes)
+		return
+	}
+	*changes = append(*changes, Change{Kind: Modified, Path: path, Old: diffValue(a), New: diffValue(b)})
+}
+
+func valueArgs(vs []interface{}) Arguments {
+	args := make(Arguments, len(vs))
+	for i, v := range vs {
+		args[i] = Argument{Value: v}
+	}
+	return args
+}
+
+// diffLists diffs two lists of arguments, matching the longest common
+// subsequence of equal arguments. Between matches, deleted and inserted
+// arguments are paired up and diffed, and the remainder reported as
+// deleted or inserted.
+func diffLists(path string, as, bs Arguments, changes *[]Change) {
+	akeys := make([]string, len(as))
+	for i, a := range as {
+		akeys[i] = string(canonicalArg(a))
+	}
+	bkeys := make([]string, len(bs))
+	for i, b := range bs {
+		bkeys[i] = string(canonicalArg(b))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// as[i:] and bs[j:].
+	lcs := make([][]int, len(as)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bs)+1)
+	}
+	for i := len(as) - 1; i >= 0; i-- {
+		for j := len(bs) - 1; j >= 0; j-- {
+			switch {
+			case akeys[i] == bkeys[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// flush diffs the unmatched arguments as[ai:i] and bs[bj:j].
+	flush := func(ai, i, bj, j int) {
+		for ; ai < i && bj < j; ai, bj = ai+1, bj+1 {
+			diffArgs(fmt.Sprintf("%s[%d]", path, bj), as[ai], bs[bj], changes)
+		}
+		for ; ai < i; ai++ {
+			*changes = append(*changes, Change{Kind: Deleted, Path: fmt.Sprintf("%s[%d]", path, ai), Old: diffValue(as[ai])})
+		}
+		for ; bj < j; bj++ {
+			*changes = append(*changes, Change{Kind: Inserted, Path: fmt.Sprintf("%s[%d]", path, bj), New: diffValue(bs[bj])})
+		}
+	}
+
+	i, j, ai, bj := 0, 0, 0, 0
+	for i < len(as) && j < len(bs) {
+		switch {
+		case akeys[i] == bkeys[j]:
+			flush(ai, i, bj, j)
+			i, j = i+1, j+1
+			ai, bj = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	flush(ai, len(as), bj, len(bs))
+}
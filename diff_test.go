@@ -0,0 +1,109 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type test struct {
+		name   string
+		a, b   string
+		expect string
+	}
+
+	tests := []test{
+		{
+			name: "unchanged",
+			a:    `{"and": [{"var": "a"}, {">": [{"var": "age"}, 18]}]}`,
+			b:    `{"and":[{"var":["a"]},{">":[{"var":"age"},18.0]}]}`,
+		},
+		{
+			name:   "threshold",
+			a:      `{"and": [{"var": "a"}, {">": [{"var": "age"}, 18]}]}`,
+			b:      `{"and": [{"var": "a"}, {">": [{"var": "age"}, 21]}]}`,
+			expect: `and[1].>[1]: 18 → 21`,
+		},
+		{
+			name:   "inserted",
+			a:      `{"and": [{"var": "a"}, {"var": "c"}]}`,
+			b:      `{"and": [{"var": "a"}, {"var": "b"}, {"var": "c"}]}`,
+			expect: `and[1]: + {"var":["b"]}`,
+		},
+		{
+			name:   "deleted",
+			a:      `{"or": [{"var": "a"}, {"var": "b"}, {"var": "c"}]}`,
+			b:      `{"or": [{"var": "b"}, {"var": "c"}]}`,
+			expect: `or[0]: - {"var":["a"]}`,
+		},
+		{
+			name: "operator-replaced",
+			a:    `{"and": [{"<": [{"var": "a"}, 1]}, true]}`,
+			b:    `{"and": [{"<=": [{"var": "a"}, 1]}, false]}`,
+			expect: `and[0]: {"<":[{"var":["a"]},1]} → {"<=":[{"var":["a"]},1]}` + "\n" +
+				`and[1]: true → false`,
+		},
+		{
+			name: "literal-array",
+			a:    `{"in": [{"var": "country"}, ["GB", "US", "FR"]]}`,
+			b:    `{"in": [{"var": "country"}, ["GB", "IE", "FR", "DE"]]}`,
+			expect: `in[1][1]: "US" → "IE"` + "\n" +
+				`in[1][3]: + "DE"`,
+		},
+		{
+			name:   "naked-array",
+			a:      `{"if": [{"var": "a"}, [{"var": "b"}, 1], 2]}`,
+			b:      `{"if": [{"var": "a"}, [{"var": "c"}, 1], 2]}`,
+			expect: `if[1][0].var[0]: "b" → "c"`,
+		},
+		{
+			name:   "root-replaced",
+			a:      `{"var": "a"}`,
+			b:      `"<b>"`,
+			expect: `{"var":["a"]} → "<b>"`,
+		},
+		{
+			name: "mixed",
+			a:    `{"if": [{"==": [{"var": "tier"}, "gold"]}, 0.2, {"==": [{"var": "tier"}, "silver"]}, 0.1, 0]}`,
+			b:    `{"if": [{"==": [{"var": "tier"}, "platinum"]}, 0.3, {"==": [{"var": "tier"}, "gold"]}, 0.2, 0]}`,
+			expect: `if[0]: + {"==":[{"var":["tier"]},"platinum"]}` + "\n" +
+				`if[1]: + 0.3` + "\n" +
+				`if[2]: - {"==":[{"var":["tier"]},"silver"]}` + "\n" +
+				`if[3]: - 0.1`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var a, b Clause
+				err := json.Unmarshal([]byte(st.a), &a)
+				assert.NoErrorf(t, err, "unmarshal error")
+				err = json.Unmarshal([]byte(st.b), &b)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				assert.Equal(t, st.expect, FormatChanges(Diff(&a, &b)))
+			})
+		})
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	var a, b Clause
+	err := json.Unmarshal([]byte(`{"and": [{">": [{"var": "age"}, 18]}, {"var": "x"}]}`), &a)
+	assert.NoError(t, err)
+	err = json.Unmarshal([]byte(`{"and": [{">": [{"var": "age"}, 21]}]}`), &b)
+	assert.NoError(t, err)
+
+	changes := Diff(&a, &b)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, Change{Kind: Modified, Path: "and[0].>[1]", Old: 18.0, New: 21.0}, changes[0])
+		assert.Equal(t, Deleted, changes[1].Kind)
+		assert.Equal(t, "and[1]", changes[1].Path)
+		assert.True(t, varArg("x").Clause.Equal(changes[1].Old.(*Clause)))
+		assert.Nil(t, changes[1].New)
+		assert.Equal(t, "deleted", changes[1].Kind.String())
+	}
+}
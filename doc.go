@@ -7,12 +7,60 @@
 // provided  by a raw json.Unmarshal. Only the following types are
 // supported:
 //   primitives of type string, float64 or bool
+//   json.Number, as produced by a json.Decoder using UseNumber
+//   all other Go integer and float types
 //   map[string]interface{} // where interface{} is a compatible type
 //   []interface{} // where interface{} is a compatible type
 //
+// Integer values (json.Number, and the Go integer types) are compared
+// without loss of precision, so large int64 IDs can be tested for equality.
+//
 // We cannot currently query Go native structs, or maps/slices of other native Go
 // types.
 //
-// Incompatibilities may exist in support for JavaScript type coercion. Any
-// incompatibilities found should be reported as bugs.
+// JavaScript type coercion (as used by ==, <, cat, substr, in and so on)
+// follows json-logic-js, and is checked against a conformance suite
+// generated from JavaScript, in testdata/coercion. The known exceptions are
+// that integers and json.Numbers are compared exactly (see above), that
+// json.Numbers are rendered as strings as written, and that a substr
+// result that would split a surrogate pair ends in U+FFFD, as Go strings
+// can not hold unpaired surrogates. Any other incompatibilities found
+// should be reported as bugs.
+//
+// Services that evaluate untrusted rules can use SafeCompile, which returns
+// an *OpError naming the operation, rather than panicking, if a built in or
+// custom operation panics while a rule is compiled or evaluated.
+//
+// Rules that are fixed at build time can be generated as Go code with
+// GenerateGo, or the jsonlogic-gen command for use with go generate, along
+// with a test checking the generated code against the rule.
+//
+// Rules can also be written in a readable expression syntax, such as
+// age >= 18 and country in ["GB", "US"], with ParseExpr, and rendered in it
+// with FormatExpr.
+//
+// A RuleSet compiles a set of named rules, with priorities, tags and
+// payloads, and evaluates them together, returning the first rule that
+// matches, all of them, or the rules ordered by score.
+//
+// Decision tables, read from JSON or CSV with ReadDecisionTableCSV, hold
+// rows of conditions on a set of inputs, and outputs, and are compiled by
+// NewDecisionTable, which lints the table, reporting the overlapping and
+// unreachable rows it finds for the table's hit policy.
+//
+// A Store holds the rules from a directory of .json files, and reloads
+// them as they change, keeping the last good version of any rule whose file
+// fails to load.
+//
+// Services compiling the same rules repeatedly can use a CompileCache,
+// which holds the most recently used compiled rules, keyed by their Hash.
+// Clause.Hash and Clause.Equal compare the structure of rules, ignoring
+// differences in how they were written, such as whitespace, wrapping single
+// arguments in arrays, and writing 1 as 1.0. Diff lists the changes between
+// two versions of a rule, addressed by paths such as and[1].>[1], and
+// FormatChanges renders them.
+//
+// Simplify rewrites a rule to a simpler one with the same result, such as
+// by flattening nested and and or operations, removing double negations,
+// and resolving if branches with literal conditions.
 package jsonlogic
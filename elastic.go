@@ -0,0 +1,322 @@
+package jsonlogic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrElasticUnsupported is wrapped by the errors ToElastic returns for rules
+// that can not be expressed in the Elasticsearch query DSL.
+var ErrElasticUnsupported = errors.New("can not be expressed as an elasticsearch query")
+
+// ElasticOptions configures ToElastic.
+type ElasticOptions struct {
+	// Fields maps var paths to field names. If Fields is nil, var paths are
+	// used as field names. Otherwise, vars that are not mapped are an error.
+	Fields map[string]string
+}
+
+// ToElastic translates a rule into an Elasticsearch query, using the bool,
+// range, term, terms and exists queries, for use in a filter context. The
+// supported operations are and, or, !, !!, var, the equality and
+// comparison operations between a var and a constant, in on a var and a
+// constant array, and missing. Errors for anything else wrap
+// ErrElasticUnsupported.
+//
+// Queries follow Elasticsearch semantics. In particular, values are not
+// coerced between types, a field holding an array matches if any value
+// matches, and a var used as a condition matches the term true, so should
+// be a boolean field.
+func ToElastic(c *Clause, opts ElasticOptions) (map[string]interface{}, error) {
+	b := &elasticBuilder{fields: opts.Fields}
+	return b.query(Argument{Clause: c})
+}
+
+type elasticBuilder struct {
+	fields map[string]string
+}
+
+func elasticUnsupported(what string) error {
+	return fmt.Errorf("%s %w", what, ErrElasticUnsupported)
+}
+
+func elasticQuery(kind string, body interface{}) map[string]interface{} {
+	return map[string]interface{}{kind: body}
+}
+
+func elasticMatchAll() map[string]interface{} {
+	return elasticQuery("match_all", map[string]interface{}{})
+}
+
+func elasticMatchNone() map[string]interface{} {
+	return elasticQuery("match_none", map[string]interface{}{})
+}
+
+func elasticBool(clause string, queries []interface{}) map[string]interface{} {
+	body := map[string]interface{}{clause: queries}
+	if clause == "should" {
+		body["minimum_should_match"] = 1
+	}
+	return elasticQuery("bool", body)
+}
+
+func elasticNot(q map[string]interface{}) map[string]interface{} {
+	return elasticBool("must_not", []interface{}{q})
+}
+
+// elasticNegate negates a query, unwrapping a must_not of a single query
+// rather than nesting it, so that !missing is an exists query.
+func elasticNegate(q map[string]interface{}) map[string]interface{} {
+	if boolq, ok := q["bool"].(map[string]interface{}); ok && len(boolq) == 1 {
+		if nots, ok := boolq["must_not"].([]interface{}); ok && len(nots) == 1 {
+			return nots[0].(map[string]interface{})
+		}
+	}
+	return elasticNot(q)
+}
+
+func elasticExists(field string) map[string]interface{} {
+	return elasticQuery("exists", map[string]interface{}{"field": field})
+}
+
+func elasticTerm(field string, v interface{}) map[string]interface{} {
+	return elasticQuery("term", map[string]interface{}{field: v})
+}
+
+// field returns the field for a var path.
+func (b *elasticBuilder) field(path string) (string, error) {
+	if path == "" {
+		return "", elasticUnsupported("var of the whole data")
+	}
+	if b.fields == nil {
+		return path, nil
+	}
+	f, ok := b.fields[path]
+	if !ok {
+		return "", fmt.Errorf("no elasticsearch field is mapped for var %q", path)
+	}
+	return f, nil
+}
+
+// varField returns the field of a var argument, which must have a constant
+// path, and no default. ok is false if arg is not a var.
+func (b *elasticBuilder) varField(arg Argument) (string, bool, error) {
+	path, ok := constantVarPath(arg)
+	if !ok {
+		return "", false, nil
+	}
+	f, err := b.field(path)
+	return f, true, err
+}
+
+func (b *elasticBuilder) queries(args Arguments) ([]interface{}, error) {
+	qs := make([]interface{}, len(args))
+	for i, a := range args {
+		q, err := b.query(a)
+		if err != nil {
+			return nil, err
+		}
+		qs[i] = q
+	}
+	return qs, nil
+}
+
+func (b *elasticBuilder) query(arg Argument) (map[string]interface{}, error) {
+	if v, ok := constantArg(arg); ok {
+		if IsTrue(v) {
+			return elasticMatchAll(), nil
+		}
+		return elasticMatchNone(), nil
+	}
+
+	op, args := arg.Clause.Operator.Name, arg.Clause.Arguments
+	switch op {
+	case varOp:
+		f, ok, err := b.varField(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, elasticUnsupported("var with a computed path or default")
+		}
+		return elasticTerm(f, true), nil
+	case andOp, orOp:
+		if len(args) == 0 {
+			return elasticMatchNone(), nil
+		}
+		qs, err := b.queries(args)
+		if err != nil {
+			return nil, err
+		}
+		if op == andOp {
+			return elasticBool("filter", qs), nil
+		}
+		return elasticBool("should", qs), nil
+	case negateOp:
+		if len(args) == 0 {
+			return elasticMatchAll(), nil
+		}
+		return b.negate(args[0])
+	case doubleNegateOp:
+		if len(args) == 0 {
+			return elasticMatchNone(), nil
+		}
+		return b.query(args[0])
+	case equalOp, equalThreeOp, notEqualOp, notEqualThreeOp:
+		return b.equality(op, args)
+	case lessOp, lessEqOp, greaterOp, greaterEqOp:
+		return b.comparison(op, args)
+	case inOp:
+		return b.in(args)
+	case missingOp:
+		paths, ok := missingPaths(args)
+		if !ok {
+			return nil, elasticUnsupported("missing with computed paths")
+		}
+		qs := make([]interface{}, len(paths))
+		for i, p := range paths {
+			f, err := b.field(p)
+			if err != nil {
+				return nil, err
+			}
+			qs[i] = elasticNot(elasticExists(f))
+		}
+		switch len(qs) {
+		case 0:
+			return elasticMatchNone(), nil
+		case 1:
+			return qs[0].(map[string]interface{}), nil
+		default:
+			return elasticBool("should", qs), nil
+		}
+	default:
+		return nil, elasticUnsupported(fmt.Sprintf("operation %q", op))
+	}
+}
+
+// negate translates the negation of arg.
+func (b *elasticBuilder) negate(arg Argument) (map[string]interface{}, error) {
+	q, err := b.query(arg)
+	if err != nil {
+		return nil, err
+	}
+	return elasticNegate(q), nil
+}
+
+// operands returns the field and constant of a comparison between a var
+// and a constant, and whether they were swapped.
+func (b *elasticBuilder) operands(op string, args Arguments) (string, interface{}, bool, error) {
+	if len(args) == 2 {
+		for i := range args {
+			f, ok, err := b.varField(args[i])
+			if err != nil {
+				return "", nil, false, err
+			}
+			if v, isconst := constantArg(args[1-i]); ok && isconst {
+				return f, v, i == 1, nil
+			}
+		}
+	}
+	return "", nil, false, elasticUnsupported(fmt.Sprintf("operation %q other than between a var and a constant", op))
+}
+
+func (b *elasticBuilder) equality(op string, args Arguments) (map[string]interface{}, error) {
+	f, v, _, err := b.operands(op, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var q map[string]interface{}
+	switch v.(type) {
+	case nil:
+		q = elasticNot(elasticExists(f))
+	case []interface{}, map[string]interface{}:
+		return nil, elasticUnsupported(fmt.Sprintf("operation %q on an array or object", op))
+	default:
+		q = elasticTerm(f, v)
+	}
+
+	if op == notEqualOp || op == notEqualThreeOp {
+		return elasticNegate(q), nil
+	}
+	return q, nil
+}
+
+var elasticRangeOps = map[string]string{
+	lessOp:      "lt",
+	lessEqOp:    "lte",
+	greaterOp:   "gt",
+	greaterEqOp: "gte",
+}
+
+func (b *elasticBuilder) comparison(op string, args Arguments) (map[string]interface{}, error) {
+	if len(args) > 2 && (op == lessOp || op == lessEqOp) {
+		// A between test, lower < var < upper
+		f, ok, err := b.varField(args[1])
+		if err != nil {
+			return nil, err
+		}
+		lower, lok := constantArg(args[0])
+		upper, uok := constantArg(args[2])
+		if !ok || !lok || !uok {
+			return nil, elasticUnsupported(fmt.Sprintf("operation %q other than a var between constants", op))
+		}
+		return elasticQuery("range", map[string]interface{}{
+			f: map[string]interface{}{
+				elasticRangeOps[flippedCompareOps[op]]: lower,
+				elasticRangeOps[op]:                    upper,
+			},
+		}), nil
+	}
+
+	f, v, swapped, err := b.operands(op, args)
+	if err != nil {
+		return nil, err
+	}
+	if swapped {
+		op = flippedCompareOps[op]
+	}
+	return elasticQuery("range", map[string]interface{}{
+		f: map[string]interface{}{elasticRangeOps[op]: v},
+	}), nil
+}
+
+func (b *elasticBuilder) in(args Arguments) (map[string]interface{}, error) {
+	if len(args) == 2 {
+		f, ok, err := b.varField(args[0])
+		if err != nil {
+			return nil, err
+		}
+		v, isconst := constantArg(args[1])
+		vals, isslice := v.([]interface{})
+		if ok && isconst && isslice {
+			// terms can not match null, which is missing.
+			terms := make([]interface{}, 0, len(vals))
+			hasNull := false
+			for _, v := range vals {
+				if v == nil {
+					hasNull = true
+					continue
+				}
+				terms = append(terms, v)
+			}
+
+			var qs []interface{}
+			if len(terms) != 0 {
+				qs = append(qs, elasticQuery("terms", map[string]interface{}{f: terms}))
+			}
+			if hasNull {
+				qs = append(qs, elasticNot(elasticExists(f)))
+			}
+			switch len(qs) {
+			case 0:
+				return elasticMatchNone(), nil
+			case 1:
+				return qs[0].(map[string]interface{}), nil
+			default:
+				return elasticBool("should", qs), nil
+			}
+		}
+	}
+	return nil, elasticUnsupported("in other than of a var in a constant array")
+}
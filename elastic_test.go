@@ -0,0 +1,129 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+func TestToElastic(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		fields map[string]string
+		err    string
+	}
+
+	// The expected query for each test is in testdata/elastic/<name>.json,
+	// and may be regenerated by running the tests with -update.
+	tests := []test{
+		{name: "true", rule: `true`},
+		{name: "false", rule: `false`},
+		{name: "var", rule: `{"var":"active"}`},
+		{name: "equal", rule: `{"==":[{"var":"country"},"GB"]}`},
+		{name: "equal-constant-first", rule: `{"===":[3,{"var":"visits"}]}`},
+		{name: "equal-null", rule: `{"==":[{"var":"email"},null]}`},
+		{name: "not-equal", rule: `{"!=":[{"var":"plan"},"free"]}`},
+		{name: "not-equal-null", rule: `{"!==":[{"var":"email"},null]}`},
+		{name: "range", rule: `{">=":[{"var":"age"},18]}`},
+		{name: "range-constant-first", rule: `{"<":[18,{"var":"age"}]}`},
+		{name: "between", rule: `{"<=":[1,{"var":"n"},10]}`},
+		{name: "in", rule: `{"in":[{"var":"country"},["GB","FR"]]}`},
+		{name: "in-with-null", rule: `{"in":[{"var":"country"},["GB",null]]}`},
+		{name: "in-empty", rule: `{"in":[{"var":"country"},[]]}`},
+		{name: "missing", rule: `{"missing":"email"}`},
+		{name: "missing-many", rule: `{"missing":["email","phone"]}`},
+		{name: "not-missing", rule: `{"!":{"missing":["email"]}}`},
+		{
+			name: "segment",
+			rule: `{"and":[
+				{">=":[{"var":"session.pages"},3]},
+				{"or":[{"in":[{"var":"geo.country"},["GB","IE"]]},{"var":"user.vip"}]},
+				{"!":{"==":[{"var":"device.type"},"bot"]}}
+			]}`,
+		},
+		{
+			name:   "mapped-fields",
+			rule:   `{"and":[{"==":[{"var":"user.country"},"GB"]},{"!!":{"var":"user.vip"}}]}`,
+			fields: map[string]string{"user.country": "geo.country_code", "user.vip": "flags.vip"},
+		},
+		{
+			name:   "unmapped-field",
+			rule:   `{"==":[{"var":"user.age"},1]}`,
+			fields: map[string]string{"user.country": "geo.country_code"},
+			err:    `no elasticsearch field is mapped for var "user.age"`,
+		},
+		{
+			name: "two-vars",
+			rule: `{"<":[{"var":"a"},{"var":"b"}]}`,
+			err:  `operation "<" other than between a var and a constant can not be expressed as an elasticsearch query`,
+		},
+		{
+			name: "computed-var",
+			rule: `{"var":{"cat":["a","b"]}}`,
+			err:  `var with a computed path or default can not be expressed as an elasticsearch query`,
+		},
+		{
+			name: "equal-array",
+			rule: `{"==":[{"var":"a"},[1]]}`,
+			err:  `operation "==" on an array or object can not be expressed as an elasticsearch query`,
+		},
+		{
+			name: "in-string",
+			rule: `{"in":["x",{"var":"a"}]}`,
+			err:  `in other than of a var in a constant array can not be expressed as an elasticsearch query`,
+		},
+		{
+			name: "unsupported",
+			rule: `{"+":[1,2]}`,
+			err:  `operation "+" can not be expressed as an elasticsearch query`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				q, err := ToElastic(&c, ElasticOptions{Fields: st.fields})
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					return
+				}
+				assert.NoError(t, err)
+
+				bs, err := json.MarshalIndent(q, "", "  ")
+				assert.NoError(t, err)
+				bs = append(bs, '\n')
+
+				golden := filepath.Join("testdata", "elastic", st.name+".json")
+				if *updateGolden {
+					assert.NoError(t, os.WriteFile(golden, bs, 0644))
+					return
+				}
+				expect, err := os.ReadFile(golden)
+				if assert.NoError(t, err) {
+					assert.Equal(t, string(expect), string(bs))
+				}
+			})
+		})
+	}
+}
+
+func TestToElasticUnsupportedError(t *testing.T) {
+	var c Clause
+	err := json.Unmarshal([]byte(`{"map":[[1],{"var":""}]}`), &c)
+	assert.NoError(t, err)
+
+	_, err = ToElastic(&c, ElasticOptions{})
+	assert.True(t, errors.Is(err, ErrElasticUnsupported))
+}
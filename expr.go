@@ -0,0 +1,721 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The expression syntax is a readable alternative to writing rules as JSON,
+// for example
+//
+//	age >= 18 and country in ["GB", "US"]
+//
+// Operations are written as follows, from lowest to highest precedence.
+//
+//	a ? b : c                   ?:
+//	a or b or ...               or
+//	a and b and ...             and
+//	not a                       !
+//	a == b, a < b, a in b ...   the equality and comparison operations, and in
+//	a < b < c, a <= b <= c      between
+//	a + b + ..., a - b - ...    + and -
+//	a * b * ..., a / b, a % b   *, / and %
+//	-a                          unary -
+//
+// Vars with simple dotted paths are written as the path, such as user.age
+// or items.0. Literals are written as JSON, and arrays may hold
+// expressions. Any other operation is written as a call, such as
+// cat(first, " ", last) or var("a b", 1), with the name quoted if it is
+// not an identifier, such as "!!"(a).
+
+// ParseError is the error returned by ParseExpr for invalid expressions.
+type ParseError struct {
+	// Line and Column give the position of the error, counting from 1.
+	// Columns count runes.
+	Line, Column int
+	Msg          string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+var exprKeywords = map[string]bool{
+	"and":   true,
+	"or":    true,
+	"not":   true,
+	"in":    true,
+	"true":  true,
+	"false": true,
+	"null":  true,
+}
+
+// exprPuncts holds the punctuation of the expression syntax, with longer
+// tokens before their prefixes.
+var exprPuncts = []string{
+	"===", "!==",
+	"==", "!=", "<=", ">=",
+	"<", ">", "+", "-", "*", "/", "%",
+	"(", ")", "[", "]", "{", "}", ",", ":", "?",
+}
+
+var exprCompareOps = map[string]bool{
+	equalOp:         true,
+	equalThreeOp:    true,
+	notEqualOp:      true,
+	notEqualThreeOp: true,
+	lessOp:          true,
+	lessEqOp:        true,
+	greaterOp:       true,
+	greaterEqOp:     true,
+	inOp:            true,
+}
+
+type exprTokenKind int
+
+const (
+	exprEOF exprTokenKind = iota
+	exprNumber
+	exprString
+	exprIdent
+	exprPunct
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+	pos  int
+}
+
+func (t exprToken) String() string {
+	if t.kind == exprEOF {
+		return "end of input"
+	}
+	return strconv.Quote(t.text)
+}
+
+func isExprIdentStart(b byte) bool {
+	return b == '_' || b == '$' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+func isExprIdentChar(b byte) bool {
+	return isExprIdentStart(b) || ('0' <= b && b <= '9')
+}
+
+func isExprDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// parseErrorAt returns a ParseError for the byte offset pos in src.
+func parseErrorAt(src string, pos int, format string, args ...interface{}) *ParseError {
+	before := src[:pos]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return &ParseError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	i := 0
+	for {
+		for i < len(src) && strings.IndexByte(" \t\r\n", src[i]) >= 0 {
+			i++
+		}
+		if i == len(src) {
+			return append(toks, exprToken{kind: exprEOF, pos: i}), nil
+		}
+
+		start := i
+		switch b := src[i]; {
+		case isExprDigit(b):
+			for i < len(src) && (isExprDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				i++
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				for i < len(src) && isExprDigit(src[i]) {
+					i++
+				}
+			}
+			if _, err := strconv.ParseFloat(src[start:i], 64); err != nil {
+				return nil, parseErrorAt(src, start, "invalid number %q", src[start:i])
+			}
+			toks = append(toks, exprToken{kind: exprNumber, text: src[start:i], pos: start})
+		case b == '"':
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, parseErrorAt(src, start, "unterminated string")
+			}
+			i++
+			toks = append(toks, exprToken{kind: exprString, text: src[start:i], pos: start})
+		case isExprIdentStart(b):
+			for i < len(src) && isExprIdentChar(src[i]) {
+				i++
+			}
+			// Dotted paths, such as user.address.0
+			for i+1 < len(src) && src[i] == '.' && isExprIdentChar(src[i+1]) {
+				i++
+				for i < len(src) && isExprIdentChar(src[i]) {
+					i++
+				}
+			}
+			toks = append(toks, exprToken{kind: exprIdent, text: src[start:i], pos: start})
+		default:
+			punct := ""
+			for _, p := range exprPuncts {
+				if strings.HasPrefix(src[i:], p) {
+					punct = p
+					break
+				}
+			}
+			if punct == "" {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+				return nil, parseErrorAt(src, start, "unexpected character %q", r)
+			}
+			i += len(punct)
+			toks = append(toks, exprToken{kind: exprPunct, text: punct, pos: start})
+		}
+	}
+}
+
+// ParseExpr parses a rule written in the expression syntax. Errors for
+// invalid expressions are a *ParseError, giving the position of the error.
+func ParseExpr(src string) (*Clause, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{src: src, toks: toks}
+	arg, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != exprEOF {
+		return nil, p.errorf(t, "unexpected %s", t)
+	}
+	if arg.Clause != nil {
+		return arg.Clause, nil
+	}
+	return &Clause{Arguments: Arguments{arg}}, nil
+}
+
+type exprParser struct {
+	src  string
+	toks []exprToken
+	i    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.toks[p.i]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.toks[p.i]
+	if t.kind != exprEOF {
+		p.i++
+	}
+	return t
+}
+
+// is reports whether the next token is the given punctuation or keyword.
+func (p *exprParser) is(text string) bool {
+	t := p.peek()
+	if exprKeywords[text] {
+		return t.kind == exprIdent && t.text == text
+	}
+	return t.kind == exprPunct && t.text == text
+}
+
+func (p *exprParser) errorf(t exprToken, format string, args ...interface{}) error {
+	return parseErrorAt(p.src, t.pos, format, args...)
+}
+
+func (p *exprParser) expect(text string) error {
+	if !p.is(text) {
+		t := p.peek()
+		return p.errorf(t, "expected %q, found %s", text, t)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) ternary() (Argument, error) {
+	cond, err := p.or()
+	if err != nil || !p.is("?") {
+		return cond, err
+	}
+	p.next()
+	then, err := p.ternary()
+	if err != nil {
+		return Argument{}, err
+	}
+	if err := p.expect(":"); err != nil {
+		return Argument{}, err
+	}
+	els, err := p.ternary()
+	if err != nil {
+		return Argument{}, err
+	}
+	return opArg(ternaryOp, cond, then, els), nil
+}
+
+// list parses operands separated by the keyword op, combining them into
+// a single operation if there is more than one.
+func (p *exprParser) list(op string, operand func() (Argument, error)) (Argument, error) {
+	first, err := operand()
+	if err != nil {
+		return Argument{}, err
+	}
+	args := []Argument{first}
+	for p.is(op) {
+		p.next()
+		a, err := operand()
+		if err != nil {
+			return Argument{}, err
+		}
+		args = append(args, a)
+	}
+	if len(args) == 1 {
+		return first, nil
+	}
+	return opArg(op, args...), nil
+}
+
+func (p *exprParser) or() (Argument, error) {
+	return p.list(orOp, p.and)
+}
+
+func (p *exprParser) and() (Argument, error) {
+	return p.list(andOp, p.not)
+}
+
+func (p *exprParser) not() (Argument, error) {
+	if !p.is("not") {
+		return p.comparison()
+	}
+	p.next()
+	a, err := p.not()
+	if err != nil {
+		return Argument{}, err
+	}
+	return opArg(negateOp, a), nil
+}
+
+// compareOp returns the comparison operation of the next token, if any.
+func (p *exprParser) compareOp() (string, bool) {
+	t := p.peek()
+	if (t.kind == exprPunct || t.kind == exprIdent) && exprCompareOps[t.text] {
+		return t.text, true
+	}
+	return "", false
+}
+
+func (p *exprParser) comparison() (Argument, error) {
+	l, err := p.additive()
+	if err != nil {
+		return Argument{}, err
+	}
+	op, ok := p.compareOp()
+	if !ok {
+		return l, nil
+	}
+	p.next()
+	r, err := p.additive()
+	if err != nil {
+		return Argument{}, err
+	}
+	args := []Argument{l, r}
+
+	if next, ok := p.compareOp(); ok {
+		// Only a < b < c and a <= b <= c, which are between, may be chained.
+		if next != op || (op != lessOp && op != lessEqOp) {
+			return Argument{}, p.errorf(p.peek(), "comparisons can not be chained, other than as a < b < c or a <= b <= c")
+		}
+		p.next()
+		upper, err := p.additive()
+		if err != nil {
+			return Argument{}, err
+		}
+		args = append(args, upper)
+		if _, ok := p.compareOp(); ok {
+			return Argument{}, p.errorf(p.peek(), "comparisons can not be chained, other than as a < b < c or a <= b <= c")
+		}
+	}
+	return opArg(op, args...), nil
+}
+
+// binary parses left associative operations, combining repeated uses of
+// the operations in flat into a single operation.
+func (p *exprParser) binary(ops []string, flat map[string]bool, operand func() (Argument, error)) (Argument, error) {
+	l, err := operand()
+	if err != nil {
+		return Argument{}, err
+	}
+	for {
+		op := ""
+		for _, o := range ops {
+			if p.is(o) {
+				op = o
+			}
+		}
+		if op == "" {
+			return l, nil
+		}
+
+		args := []Argument{l}
+		for len(args) == 1 || (flat[op] && p.is(op)) {
+			p.next()
+			r, err := operand()
+			if err != nil {
+				return Argument{}, err
+			}
+			args = append(args, r)
+		}
+		l = opArg(op, args...)
+	}
+}
+
+func (p *exprParser) additive() (Argument, error) {
+	return p.binary([]string{plusOp, minusOp}, map[string]bool{plusOp: true, minusOp: true}, p.multiplicative)
+}
+
+func (p *exprParser) multiplicative() (Argument, error) {
+	return p.binary([]string{multiplyOp, divideOp, moduloOp}, map[string]bool{multiplyOp: true}, p.unary)
+}
+
+func (p *exprParser) unary() (Argument, error) {
+	if !p.is(minusOp) {
+		return p.primary()
+	}
+	p.next()
+	if t := p.peek(); t.kind == exprNumber {
+		// A negative number, rather than the - operation.
+		p.next()
+		f, _ := strconv.ParseFloat(t.text, 64)
+		return Argument{Value: -f}, nil
+	}
+	a, err := p.unary()
+	if err != nil {
+		return Argument{}, err
+	}
+	return opArg(minusOp, a), nil
+}
+
+func (p *exprParser) primary() (Argument, error) {
+	t := p.next()
+	switch t.kind {
+	case exprNumber:
+		f, _ := strconv.ParseFloat(t.text, 64)
+		return Argument{Value: f}, nil
+	case exprString:
+		var s string
+		if err := json.Unmarshal([]byte(t.text), &s); err != nil {
+			return Argument{}, p.errorf(t, "invalid string %s", t.text)
+		}
+		if p.is("(") {
+			return p.call(s)
+		}
+		return Argument{Value: s}, nil
+	case exprIdent:
+		switch t.text {
+		case "true":
+			return Argument{Value: true}, nil
+		case "false":
+			return Argument{Value: false}, nil
+		case "null":
+			return Argument{Value: nil}, nil
+		}
+		if exprKeywords[t.text] {
+			return Argument{}, p.errorf(t, "unexpected %s", t)
+		}
+		if p.is("(") && !strings.Contains(t.text, ".") {
+			return p.call(t.text)
+		}
+		return varArg(t.text), nil
+	case exprPunct:
+		switch t.text {
+		case "(":
+			a, err := p.ternary()
+			if err != nil {
+				return Argument{}, err
+			}
+			return a, p.expect(")")
+		case "[":
+			return p.array()
+		case "{":
+			return p.object()
+		}
+	}
+	return Argument{}, p.errorf(t, "unexpected %s", t)
+}
+
+// items parses expressions separated by commas, up to the closing
+// punctuation.
+func (p *exprParser) items(closing string) (Arguments, error) {
+	args := Arguments{}
+	if p.is(closing) {
+		p.next()
+		return args, nil
+	}
+	for {
+		a, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if p.is(closing) {
+			p.next()
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) call(op string) (Argument, error) {
+	p.next()
+	args, err := p.items(")")
+	if err != nil {
+		return Argument{}, err
+	}
+	return Argument{Clause: &Clause{Operator: Operator{Name: op}, Arguments: args}}, nil
+}
+
+func (p *exprParser) array() (Argument, error) {
+	args, err := p.items("]")
+	if err != nil {
+		return Argument{}, err
+	}
+	vals := make([]interface{}, len(args))
+	for i, a := range args {
+		if a.Clause != nil {
+			return arrayClauseArg(args), nil
+		}
+		vals[i] = a.Value
+	}
+	return Argument{Value: vals}, nil
+}
+
+// arrayClauseArg returns an array of expressions, which is evaluated. As
+// when unmarshaling such an array, literals are wrapped in clauses, so the
+// clause is not taken to be a literal itself.
+func arrayClauseArg(args Arguments) Argument {
+	for i, a := range args {
+		if a.Clause == nil {
+			args[i] = Argument{Clause: &Clause{Arguments: Arguments{a}}}
+		}
+	}
+	return Argument{Clause: &Clause{Arguments: args}}
+}
+
+func (p *exprParser) object() (Argument, error) {
+	obj := map[string]interface{}{}
+	if p.is("}") {
+		p.next()
+		return Argument{Value: obj}, nil
+	}
+	for {
+		t := p.next()
+		var k string
+		if t.kind != exprString || json.Unmarshal([]byte(t.text), &k) != nil {
+			return Argument{}, p.errorf(t, "expected a string object key, found %s", t)
+		}
+		if err := p.expect(":"); err != nil {
+			return Argument{}, err
+		}
+		vt := p.peek()
+		v, err := p.ternary()
+		if err != nil {
+			return Argument{}, err
+		}
+		if v.Clause != nil {
+			return Argument{}, p.errorf(vt, "object values must be literals")
+		}
+		obj[k] = v.Value
+		if p.is("}") {
+			p.next()
+			return Argument{Value: obj}, nil
+		}
+		if err := p.expect(","); err != nil {
+			return Argument{}, err
+		}
+	}
+}
+
+// Precedences of the expression syntax, from lowest to highest.
+const (
+	exprPrecTernary = iota + 1
+	exprPrecOr
+	exprPrecAnd
+	exprPrecNot
+	exprPrecCompare
+	exprPrecAdd
+	exprPrecMul
+	exprPrecUnary
+	exprPrecPrimary
+)
+
+// FormatExpr renders a rule in the expression syntax, such that ParseExpr
+// returns a rule with the same JSON encoding.
+func FormatExpr(c *Clause) (string, error) {
+	return formatExpr(Argument{Clause: c}, exprPrecTernary)
+}
+
+// isExprPath reports whether a var path can be written as a bare path.
+func isExprPath(path string) bool {
+	if exprKeywords[path] || path == "" || !isExprIdentStart(path[0]) {
+		return false
+	}
+	for _, seg := range strings.Split(path, ".") {
+		if seg == "" {
+			return false
+		}
+		for i := 0; i < len(seg); i++ {
+			if !isExprIdentChar(seg[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// formatExpr formats arg, adding parentheses if it has a lower precedence
+// than prec.
+func formatExpr(arg Argument, prec int) (string, error) {
+	if v, ok := constantArg(arg); ok {
+		return formatExprLiteral(v)
+	}
+
+	s, argPrec, err := formatExprOp(arg.Clause)
+	if err != nil {
+		return "", err
+	}
+	if argPrec < prec {
+		return "(" + s + ")", nil
+	}
+	return s, nil
+}
+
+// formatExprArgs formats args, separated by sep.
+func formatExprArgs(args Arguments, sep string, prec int) (string, error) {
+	strs := make([]string, len(args))
+	for i, a := range args {
+		s, err := formatExpr(a, prec)
+		if err != nil {
+			return "", err
+		}
+		strs[i] = s
+	}
+	return strings.Join(strs, sep), nil
+}
+
+// formatExprOp formats a clause, returning its precedence.
+func formatExprOp(c *Clause) (string, int, error) {
+	op, args := c.Operator.Name, c.Arguments
+	var s string
+	var err error
+	switch {
+	case op == nullOp:
+		s, err = formatExprArgs(args, ", ", exprPrecTernary)
+		return "[" + s + "]", exprPrecPrimary, err
+	case op == varOp && len(args) == 1:
+		if path, ok := constantArg(args[0]); ok {
+			if path, ok := path.(string); ok && isExprPath(path) {
+				return path, exprPrecPrimary, nil
+			}
+		}
+	case op == ternaryOp && len(args) == 3:
+		cond, err := formatExpr(args[0], exprPrecOr)
+		if err != nil {
+			return "", 0, err
+		}
+		s, err = formatExprArgs(args[1:], " : ", exprPrecTernary)
+		return cond + " ? " + s, exprPrecTernary, err
+	case op == orOp && len(args) >= 2:
+		s, err = formatExprArgs(args, " or ", exprPrecAnd)
+		return s, exprPrecOr, err
+	case op == andOp && len(args) >= 2:
+		s, err = formatExprArgs(args, " and ", exprPrecNot)
+		return s, exprPrecAnd, err
+	case op == negateOp && len(args) == 1:
+		s, err = formatExpr(args[0], exprPrecNot)
+		return "not " + s, exprPrecNot, err
+	case exprCompareOps[op] && len(args) == 2,
+		(op == lessOp || op == lessEqOp) && len(args) == 3:
+		s, err = formatExprArgs(args, " "+op+" ", exprPrecAdd)
+		return s, exprPrecCompare, err
+	case (op == plusOp || op == minusOp) && len(args) >= 2:
+		s, err = formatExprArgs(args, " "+op+" ", exprPrecMul)
+		return s, exprPrecAdd, err
+	case op == multiplyOp && len(args) >= 2,
+		(op == divideOp || op == moduloOp) && len(args) == 2:
+		s, err = formatExprArgs(args, " "+op+" ", exprPrecUnary)
+		return s, exprPrecMul, err
+	case op == minusOp && len(args) == 1:
+		if s, err = formatExpr(args[0], exprPrecUnary); err != nil {
+			return "", 0, err
+		}
+		if isExprDigit(s[0]) || s[0] == '-' {
+			// Not a negative number, or a double negative.
+			s = "(" + s + ")"
+		}
+		return "-" + s, exprPrecUnary, nil
+	}
+
+	name := op
+	if !isExprPath(op) || strings.Contains(op, ".") {
+		bs, err := marshalUnescaped(op)
+		if err != nil {
+			return "", 0, err
+		}
+		name = string(bs)
+	}
+	s, err = formatExprArgs(args, ", ", exprPrecTernary)
+	return name + "(" + s + ")", exprPrecPrimary, err
+}
+
+func formatExprLiteral(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		strs := make([]string, len(v))
+		for i, e := range v {
+			s, err := formatExprLiteral(e)
+			if err != nil {
+				return "", err
+			}
+			strs[i] = s
+		}
+		return "[" + strings.Join(strs, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		strs := make([]string, len(keys))
+		for i, k := range keys {
+			ks, err := marshalUnescaped(k)
+			if err != nil {
+				return "", err
+			}
+			vs, err := formatExprLiteral(v[k])
+			if err != nil {
+				return "", err
+			}
+			strs[i] = string(ks) + ": " + vs
+		}
+		return "{" + strings.Join(strs, ", ") + "}", nil
+	default:
+		bs, err := marshalUnescaped(v)
+		return string(bs), err
+	}
+}
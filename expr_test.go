@@ -0,0 +1,307 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpr(t *testing.T) {
+	type test struct {
+		name   string
+		expr   string
+		expect string
+		err    string
+	}
+
+	tests := []test{
+		{
+			name:   "segment",
+			expr:   `age > 18 and country in ["GB", "US"]`,
+			expect: `{"and":[{">":[{"var":"age"},18]},{"in":[{"var":"country"},["GB","US"]]}]}`,
+		},
+		{
+			name:   "literals",
+			expr:   `[1.5, -2, 1e3, "a\"b", true, false, null, {"k": [1]}]`,
+			expect: `[1.5,-2,1000,"a\"b",true,false,null,{"k":[1]}]`,
+		},
+		{
+			name:   "literal-rule",
+			expr:   `"apple"`,
+			expect: `"apple"`,
+		},
+		{
+			name:   "dotted-path",
+			expr:   `user.address.0 == "x"`,
+			expect: `{"==":[{"var":"user.address.0"},"x"]}`,
+		},
+		{
+			name:   "precedence",
+			expr:   `a or b and not c == 1 + 2 * 3`,
+			expect: `{"or":[{"var":"a"},{"and":[{"var":"b"},{"!":{"==":[{"var":"c"},{"+":[1,{"*":[2,3]}]}]}}]}]}`,
+		},
+		{
+			name:   "parentheses",
+			expr:   `(a or b) and c`,
+			expect: `{"and":[{"or":[{"var":"a"},{"var":"b"}]},{"var":"c"}]}`,
+		},
+		{
+			name:   "flattened",
+			expr:   `a + b + c - d - e`,
+			expect: `{"-":[{"+":[{"var":"a"},{"var":"b"},{"var":"c"}]},{"var":"d"},{"var":"e"}]}`,
+		},
+		{
+			name:   "nested",
+			expr:   `(a + b) + c`,
+			expect: `{"+":[{"+":[{"var":"a"},{"var":"b"}]},{"var":"c"}]}`,
+		},
+		{
+			name:   "divide-left-associative",
+			expr:   `a / b / c`,
+			expect: `{"/":[{"/":[{"var":"a"},{"var":"b"}]},{"var":"c"}]}`,
+		},
+		{
+			name:   "unary-minus",
+			expr:   `-a - -1 - -(1)`,
+			expect: `{"-":[{"-":[{"var":"a"}]},-1,{"-":[1]}]}`,
+		},
+		{
+			name:   "between",
+			expr:   `1 <= n <= 10`,
+			expect: `{"<=":[1,{"var":"n"},10]}`,
+		},
+		{
+			name:   "ternary",
+			expr:   `a ? "x" : b ? "y" : "z"`,
+			expect: `{"?:":[{"var":"a"},"x",{"?:":[{"var":"b"},"y","z"]}]}`,
+		},
+		{
+			name:   "calls",
+			expr:   `cat(first, " ", last) == var("full name", "") and "!!"(missing("a")) and if()`,
+			expect: `{"and":[{"==":[{"cat":[{"var":"first"}," ",{"var":"last"}]},{"var":["full name",""]}]},{"!!":{"missing":"a"}},{"if":[]}]}`,
+		},
+		{
+			name:   "array-of-expressions",
+			expr:   `[a, 1]`,
+			expect: `[{"var":"a"},1]`,
+		},
+		{
+			name:   "keyword-prefixed-path",
+			expr:   `true.x or notice`,
+			expect: `{"or":[{"var":"true.x"},{"var":"notice"}]}`,
+		},
+		{
+			name: "unexpected-end",
+			expr: `a and`,
+			err:  `line 1, column 6: unexpected end of input`,
+		},
+		{
+			name: "unexpected-token",
+			expr: "a ==\n  == b",
+			err:  `line 2, column 3: unexpected "=="`,
+		},
+		{
+			name: "chained-comparison",
+			expr: `a == b == c`,
+			err:  `line 1, column 8: comparisons can not be chained, other than as a < b < c or a <= b <= c`,
+		},
+		{
+			name: "mixed-between",
+			expr: `1 < a <= 2`,
+			err:  `line 1, column 7: comparisons can not be chained, other than as a < b < c or a <= b <= c`,
+		},
+		{
+			name: "unclosed-call",
+			expr: `cat(a, b`,
+			err:  `line 1, column 9: expected ",", found end of input`,
+		},
+		{
+			name: "unterminated-string",
+			expr: `a == "é`,
+			err:  `line 1, column 6: unterminated string`,
+		},
+		{
+			name: "unexpected-character",
+			expr: `é == !a`,
+			err:  `line 1, column 1: unexpected character 'é'`,
+		},
+		{
+			name: "column-counts-runes",
+			expr: `"é" == !a`,
+			err:  `line 1, column 8: unexpected character '!'`,
+		},
+		{
+			name: "invalid-number",
+			expr: `1.2.3`,
+			err:  `line 1, column 1: invalid number "1.2.3"`,
+		},
+		{
+			name: "object-value",
+			expr: `{"k": a}`,
+			err:  `line 1, column 7: object values must be literals`,
+		},
+		{
+			name: "trailing-input",
+			expr: `a b`,
+			err:  `line 1, column 3: unexpected "b"`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				c, err := ParseExpr(st.expr)
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					var perr *ParseError
+					assert.True(t, errors.As(err, &perr))
+					return
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				var expect Clause
+				err = json.Unmarshal([]byte(st.expect), &expect)
+				assert.NoErrorf(t, err, "unmarshal error")
+				assertSameRule(t, &expect, c)
+			})
+		})
+	}
+}
+
+func TestFormatExpr(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		expect string
+	}
+
+	tests := []test{
+		{
+			name:   "segment",
+			rule:   `{"and":[{">":[{"var":"age"},18]},{"in":[{"var":"country"},["GB","US"]]}]}`,
+			expect: `age > 18 and country in ["GB", "US"]`,
+		},
+		{
+			name:   "precedence",
+			rule:   `{"and":[{"or":[{"var":"a"},{"var":"b"}]},{"!":{"and":[{"var":"c"},{"var":"d"}]}}]}`,
+			expect: `(a or b) and not (c and d)`,
+		},
+		{
+			name:   "nested-arithmetic",
+			rule:   `{"*":[{"+":[{"var":"a"},1]},{"-":[{"-":[{"var":"b"},2]},3]}]}`,
+			expect: `(a + 1) * ((b - 2) - 3)`,
+		},
+		{
+			name:   "unary-minus",
+			rule:   `{"-":[{"-":[1]},{"-":[-1]},{"-":[{"var":"a"}]}]}`,
+			expect: `-(1) - -(-1) - -a`,
+		},
+		{
+			name:   "not-comparison",
+			rule:   `{"==":[{"!":{"var":"a"}},false]}`,
+			expect: `(not a) == false`,
+		},
+		{
+			name:   "ternary",
+			rule:   `{"?:":[{"?:":[{"var":"a"},true,false]},{"var":"b"},{"?:":[{"var":"c"},1,2]}]}`,
+			expect: `(a ? true : false) ? b : c ? 1 : 2`,
+		},
+		{
+			name:   "calls",
+			rule:   `{"and":[{"!!":[{"var":"a"}]},{"var":"in"},{"var":["a",1]},{"if":[]},{"or":[{"var":"a"}]}]}`,
+			expect: `"!!"(a) and var("in") and var("a", 1) and if() and "or"(a)`,
+		},
+		{
+			name:   "literals",
+			rule:   `{"in":[{"var":"a"},[1.5,"<b>",null,{"y":[],"x":1}]]}`,
+			expect: `a in [1.5, "<b>", null, {"x": 1, "y": []}]`,
+		},
+		{
+			name:   "array-of-expressions",
+			rule:   `[{"var":"a"},[1,2]]`,
+			expect: `[a, [1, 2]]`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				s, err := FormatExpr(&c)
+				assert.NoError(t, err)
+				assert.Equal(t, st.expect, s)
+			})
+		})
+	}
+}
+
+// emptyNilArguments replaces nil arguments, as unmarshaled from
+// {"op":null}, with empty arguments, which evaluate the same.
+func emptyNilArguments(c *Clause) {
+	if c.Arguments == nil {
+		c.Arguments = Arguments{}
+	}
+	for _, a := range c.Arguments {
+		if a.Clause != nil {
+			emptyNilArguments(a.Clause)
+		}
+	}
+}
+
+// assertSameRule asserts two rules have the same JSON encoding, other than
+// for nil arguments.
+func assertSameRule(t *testing.T, expect, actual *Clause) bool {
+	emptyNilArguments(expect)
+	emptyNilArguments(actual)
+	ebs, err := json.Marshal(expect)
+	assert.NoError(t, err)
+	abs, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	return assert.JSONEq(t, string(ebs), string(abs))
+}
+
+func TestExprRoundTrip(t *testing.T) {
+	tests := []json.RawMessage{}
+	bs, err := os.ReadFile("testdata/tests.json")
+	if err != nil {
+		t.Fatalf("could not open testfile, %v", err)
+	}
+	if err = json.Unmarshal(bs, &tests); err != nil {
+		t.Fatalf("could not unmarshal testdata, %v", err)
+	}
+
+	for i, tline := range tests {
+		var details [3]json.RawMessage
+		if err := json.Unmarshal(tline, &details); err != nil {
+			continue
+		}
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var c Clause
+			err := json.Unmarshal(details[0], &c)
+			assert.NoErrorf(t, err, "unmarshal error")
+
+			s, err := FormatExpr(&c)
+			if !assert.NoError(t, err) {
+				return
+			}
+			rt, err := ParseExpr(s)
+			if !assert.NoErrorf(t, err, "parsing %s", s) {
+				return
+			}
+			if assertSameRule(t, &c, rt) {
+				rts, err := FormatExpr(rt)
+				assert.NoError(t, err)
+				assert.Equal(t, s, rts)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonlogic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// FuzzClause parses a rule, checks that it survives a round trip through
+// MarshalJSON, and through the expression syntax, then compiles it and
+// evaluates it against the data. The seed
+// corpus in testdata/fuzz/FuzzClause holds the rules and data of
+// testdata/tests.json.
+func FuzzClause(f *testing.F) {
+	ctx := context.Background()
+
+	f.Fuzz(func(t *testing.T, rule, data []byte) {
+		var cls Clause
+		if err := json.Unmarshal(rule, &cls); err != nil {
+			return
+		}
+
+		bs, err := json.Marshal(cls)
+		if err != nil {
+			t.Fatalf("could not marshal clause parsed from %q, %v", rule, err)
+		}
+
+		var rt Clause
+		if err := json.Unmarshal(bs, &rt); err != nil {
+			t.Fatalf("could not unmarshal marshaled clause %q, %v", bs, err)
+		}
+		rtbs, err := json.Marshal(rt)
+		if err != nil {
+			t.Fatalf("could not marshal round tripped clause %q, %v", bs, err)
+		}
+		if !bytes.Equal(bs, rtbs) {
+			t.Fatalf("unstable round trip of %q, %q != %q", rule, bs, rtbs)
+		}
+
+		expr, err := FormatExpr(&rt)
+		if err != nil {
+			t.Fatalf("could not format clause %q, %v", bs, err)
+		}
+		exprrt, err := ParseExpr(expr)
+		if err != nil {
+			t.Fatalf("could not parse formatted clause %q, %v", expr, err)
+		}
+		emptyNilArguments(&rt)
+		emptyNilArguments(exprrt)
+		rtbs, _ = json.Marshal(rt)
+		exprbs, err := json.Marshal(exprrt)
+		if err != nil || !bytes.Equal(rtbs, exprbs) {
+			t.Fatalf("unstable round trip of %q through %q, %q != %q", bs, expr, rtbs, exprbs)
+		}
+
+		cf, err := Compile(&cls)
+		if err != nil {
+			return
+		}
+
+		var d interface{}
+		if err := json.Unmarshal(data, &d); err != nil {
+			d = nil
+		}
+		cf(ctx, d)
+	})
+}
+
+// FuzzExpr parses an expression, and checks that formatting and parsing
+// the rule again gives the same rule.
+func FuzzExpr(f *testing.F) {
+	for _, expr := range []string{
+		`age > 18 and country in ["GB", "US"]`,
+		`a ? -b * (c + 1) : not d <= 1e3 <= e`,
+		`cat(first, " ", last) == var("full name", "") or "!!"([a, {"k": null}])`,
+	} {
+		f.Add(expr)
+	}
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		c, err := ParseExpr(expr)
+		if err != nil {
+			return
+		}
+		formatted, err := FormatExpr(c)
+		if err != nil {
+			t.Fatalf("could not format rule parsed from %q, %v", expr, err)
+		}
+		rt, err := ParseExpr(formatted)
+		if err != nil {
+			t.Fatalf("could not parse %q formatted from %q, %v", formatted, expr, err)
+		}
+		bs, _ := json.Marshal(c)
+		rtbs, _ := json.Marshal(rt)
+		if !bytes.Equal(bs, rtbs) {
+			t.Fatalf("unstable round trip of %q through %q, %q != %q", expr, formatted, bs, rtbs)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Hash returns a hex encoded SHA-256 hash of the rule's structure. Rules
+// that are Equal have the same hash.
+func (c *Clause) Hash() string {
+	sum := sha256.Sum256(canonicalClause(c))
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal reports whether two rules have the same structure. Differences in
+// how a rule was written are ignored, so a single argument is equal to an
+// array holding it, a literal is equal to a clause wrapping it, and
+// numbers are compared by value, so 1, 1.0 and 1e0 are equal, as are the
+// float64 1, the int 1 and json.Number("1"). json.Numbers keep their text,
+// which cat and DecimalOps use, so json.Number("1.0") is not equal to 1.
+func (c *Clause) Equal(other *Clause) bool {
+	return bytes.Equal(canonicalClause(c), canonicalClause(other))
+}
+
+// canonicalClause returns a JSON like encoding of a rule that is the same
+// for Equal rules.
+func canonicalClause(c *Clause) []byte {
+	buf := &bytes.Buffer{}
+	writeCanonicalClause(buf, c)
+	return buf.Bytes()
+}
+
+func writeCanonicalClause(buf *bytes.Buffer, c *Clause) {
+	if c == nil {
+		buf.WriteString("null")
+		return
+	}
+	if c.Operator.Name == nullOp {
+		if len(c.Arguments) == 1 && c.Arguments[0].Clause == nil {
+			writeCanonicalValue(buf, c.Arguments[0].Value)
+			return
+		}
+		writeCanonicalArgs(buf, c.Arguments)
+		return
+	}
+	buf.WriteByte('{')
+	writeCanonicalValue(buf, c.Operator.Name)
+	buf.WriteByte(':')
+	writeCanonicalArgs(buf, c.Arguments)
+	buf.WriteByte('}')
+}
+
+func writeCanonicalArgs(buf *bytes.Buffer, args Arguments) {
+	buf.WriteByte('[')
+	for i, a := range args {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if a.Clause != nil {
+			writeCanonicalClause(buf, a.Clause)
+			continue
+		}
+		writeCanonicalValue(buf, a.Value)
+	}
+	buf.WriteByte(']')
+}
+
+func writeCanonicalValue(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		buf.WriteString(strconv.Quote(v))
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalValue(buf, e)
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Quote(k))
+			buf.WriteByte(':')
+			writeCanonicalValue(buf, v[k])
+		}
+		buf.WriteByte('}')
+	default:
+		if isNumber(v) {
+			buf.WriteString(canonicalNumber(v))
+			return
+		}
+		// Other Go types, such as []string, are compared as their JSON
+		// encoding.
+		bs, err := json.Marshal(v)
+		if err != nil {
+			fmt.Fprintf(buf, "%T(%#v)", v, v)
+			return
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(bs, &decoded); err != nil {
+			fmt.Fprintf(buf, "%T(%#v)", v, v)
+			return
+		}
+		writeCanonicalValue(buf, decoded)
+	}
+}
+
+// canonicalNumber formats a number so numbers that behave the same, of
+// any type, are formatted the same. A number is formatted as its exact
+// value, keeping the sign of zero, as dividing by -0 gives -Infinity,
+// followed by its string form, as cat gives it. So 1, 1.0 and 1e0 decoded
+// as float64s, the int 1, and json.Number("1") are formatted the same, but
+// json.Number("1.0") is not, as cat gives "1.0" for it.
+func canonicalNumber(v interface{}) string {
+	str := toString(v)
+	if f32, ok := v.(float32); ok {
+		// DefaultOps reads a float32 by its binary value, while DecimalOps
+		// reads it by its shortest decimal representation, so float32(0.1)
+		// and 0.1 only behave the same under DecimalOps. The binary value
+		// keeps them apart.
+		v = float64(f32)
+	}
+
+	f := toNumber(v)
+	d, ok := toDecimal(v)
+	var value string
+	switch {
+	case !ok && math.IsNaN(f):
+		value = "NaN"
+	case !ok:
+		// Infinities
+		value = strconv.FormatFloat(f, 'g', -1, 64)
+	case d.Sign() == 0 && math.Signbit(f):
+		value = "-0"
+	default:
+		value = d.RatString()
+	}
+	return value + " " + str
+}
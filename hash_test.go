@@ -0,0 +1,160 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClauseEqual(t *testing.T) {
+	type test struct {
+		name   string
+		a, b   string
+		expect bool
+	}
+
+	tests := []test{
+		{
+			name:   "whitespace",
+			a:      `{"==": [{"var": "a"}, 1]}`,
+			b:      "{\"==\":[\n\t{\"var\":\"a\"},\n\t1\n]}",
+			expect: true,
+		},
+		{
+			name:   "single-argument",
+			a:      `{"var": "a"}`,
+			b:      `{"var": ["a"]}`,
+			expect: true,
+		},
+		{
+			name:   "numbers",
+			a:      `{"in": [1, [2.50, 1e3, -0]]}`,
+			b:      `{"in": [1.0, [2.5, 1000, -0.0]]}`,
+			expect: true,
+		},
+		{
+			name:   "null-arguments",
+			a:      `{"var": null}`,
+			b:      `{"var": []}`,
+			expect: true,
+		},
+		{
+			name:   "literal-key-order",
+			a:      `{"==": [{"var": "a"}, [{"x": 1, "y": [true, null]}]]}`,
+			b:      `{"==": [{"var": "a"}, [{"y": [true, null], "x": 1}]]}`,
+			expect: true,
+		},
+		{
+			name:   "naked-array",
+			a:      `[{"var": "a"}, 1]`,
+			b:      `[{"var": ["a"]}, 1.0]`,
+			expect: true,
+		},
+		{
+			name: "string-number",
+			a:    `{"==": [{"var": "a"}, 1]}`,
+			b:    `{"==": [{"var": "a"}, "1"]}`,
+		},
+		{
+			name: "operator",
+			a:    `{"==": [{"var": "a"}, 1]}`,
+			b:    `{"===": [{"var": "a"}, 1]}`,
+		},
+		{
+			name: "argument-order",
+			a:    `{"-": [{"var": "a"}, 1]}`,
+			b:    `{"-": [1, {"var": "a"}]}`,
+		},
+		{
+			name: "nested-array",
+			a:    `{"var": ["a"]}`,
+			b:    `{"var": [["a"]]}`,
+		},
+		{
+			name: "number-precision",
+			a:    `{"==": [{"var": "a"}, 0.1]}`,
+			b:    `{"==": [{"var": "a"}, 0.10000000000000002]}`,
+		},
+		{
+			name: "negative-zero",
+			a:    `{"/": [1, -0]}`,
+			b:    `{"/": [1, 0]}`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var a, b Clause
+				err := json.Unmarshal([]byte(st.a), &a)
+				assert.NoErrorf(t, err, "unmarshal error")
+				err = json.Unmarshal([]byte(st.b), &b)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				assert.Equal(t, st.expect, a.Equal(&b))
+				assert.Equal(t, st.expect, b.Equal(&a))
+				assert.Equal(t, st.expect, a.Hash() == b.Hash())
+				assert.True(t, a.Equal(&a))
+			})
+		})
+	}
+}
+
+func TestClauseEqualGoValues(t *testing.T) {
+	var parsed Clause
+	err := json.Unmarshal([]byte(`{"in": [{"var": "id"}, [9007199254740993, "a"]]}`), &parsed)
+	assert.NoError(t, err)
+
+	built := &Clause{
+		Operator: Operator{Name: inOp},
+		Arguments: Arguments{
+			varArg("id"),
+			{Value: []interface{}{int64(9007199254740993), "a"}},
+		},
+	}
+	// The float64 parsed from JSON can not hold the int64 exactly.
+	assert.False(t, parsed.Equal(built))
+
+	decoded := &Clause{
+		Operator: Operator{Name: inOp},
+		Arguments: Arguments{
+			varArg("id"),
+			{Value: []interface{}{json.Number("9007199254740993"), "a"}},
+		},
+	}
+	assert.True(t, built.Equal(decoded))
+	assert.Equal(t, built.Hash(), decoded.Hash())
+
+	typed := &Clause{
+		Operator:  Operator{Name: inOp},
+		Arguments: Arguments{varArg("id"), {Value: []string{"a"}}},
+	}
+	untyped := &Clause{
+		Operator:  Operator{Name: inOp},
+		Arguments: Arguments{varArg("id"), {Value: []interface{}{"a"}}},
+	}
+	assert.True(t, typed.Equal(untyped))
+
+	// Decoded numbers are compared exactly, and keep the sign of zero and
+	// their text, which cat and DecimalOps use.
+	exact := &Clause{Arguments: Arguments{{Value: json.Number("0.1000000000000000000001")}}}
+	assert.False(t, exact.Equal(&Clause{Arguments: Arguments{{Value: 0.1}}}))
+	assert.True(t, exact.Equal(&Clause{Arguments: Arguments{{Value: json.Number("0.1000000000000000000001")}}}))
+	assert.False(t, exact.Equal(&Clause{Arguments: Arguments{{Value: json.Number("1000000000000000000001e-22")}}}))
+	assert.True(t, (&Clause{Arguments: Arguments{{Value: json.Number("0.1")}}}).Equal(&Clause{Arguments: Arguments{{Value: 0.1}}}))
+	assert.True(t, (&Clause{Arguments: Arguments{{Value: json.Number("1")}}}).Equal(&Clause{Arguments: Arguments{{Value: 1}}}))
+	assert.False(t, (&Clause{Arguments: Arguments{{Value: json.Number("1.0")}}}).Equal(&Clause{Arguments: Arguments{{Value: 1.0}}}))
+	negZero := &Clause{Arguments: Arguments{{Value: json.Number("-0")}}}
+	assert.True(t, negZero.Equal(&Clause{Arguments: Arguments{{Value: json.Number("-0")}}}))
+	assert.False(t, negZero.Equal(&Clause{Arguments: Arguments{{Value: math.Copysign(0, -1)}}}))
+	assert.False(t, negZero.Equal(&Clause{Arguments: Arguments{{Value: 0}}}))
+	assert.False(t, (&Clause{Arguments: Arguments{{Value: math.Copysign(0, -1)}}}).Equal(&Clause{Arguments: Arguments{{Value: 0.0}}}))
+	assert.False(t, (&Clause{Arguments: Arguments{{Value: float32(0.1)}}}).Equal(&Clause{Arguments: Arguments{{Value: 0.1}}}))
+
+	nan := &Clause{Arguments: Arguments{{Value: math.NaN()}}}
+	assert.True(t, nan.Equal(&Clause{Arguments: Arguments{{Value: float32(math.NaN())}}}))
+	assert.False(t, nan.Equal(&Clause{Arguments: Arguments{{Value: "NaN"}}}))
+	assert.Len(t, nan.Hash(), 64)
+}
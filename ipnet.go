@@ -0,0 +1,240 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// IP address operations
+	ipInCIDROp  = "ip_in_cidr"
+	ipVersionOp = "ip_version"
+)
+
+// ipRange is an inclusive range of addresses. IPv4 ranges use the
+// 4 byte form, IPv6 ranges the 16 byte form.
+type ipRange struct {
+	first, last net.IP
+}
+
+// cidrSet is a lookup structure for a list of CIDR blocks. The blocks
+// are stored as sorted, non-overlapping ranges of addresses, per IP
+// version, so that a lookup is a binary search.
+type cidrSet struct {
+	v4 []ipRange
+	v6 []ipRange
+}
+
+// parseIP parses an IPv4 or IPv6 address, returning the 4 byte form
+// for IPv4 addresses (including IPv4-mapped IPv6 addresses).
+func parseIP(s string) net.IP {
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
+
+// parseCIDR parses a CIDR block, or a single address which is treated as
+// a /32 (IPv4) or /128 (IPv6) block.
+func parseCIDR(s string) (ipRange, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := parseIP(s)
+		if ip == nil {
+			return ipRange{}, fmt.Errorf("invalid CIDR block %q", s)
+		}
+		return ipRange{first: ip, last: ip}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return ipRange{}, fmt.Errorf("invalid CIDR block %q", s)
+	}
+
+	first := ipnet.IP
+	mask := ipnet.Mask
+	if ip4 := first.To4(); ip4 != nil && len(mask) == net.IPv6len {
+		first, mask = ip4, mask[12:]
+	}
+	last := make(net.IP, len(first))
+	for i := range first {
+		last[i] = first[i] | ^mask[i]
+	}
+	return ipRange{first: first, last: last}, nil
+}
+
+// newCIDRSet builds a cidrSet from a list of blocks. Overlapping and
+// adjacent blocks are merged.
+func newCIDRSet(blocks []string) (*cidrSet, error) {
+	set := &cidrSet{}
+	for _, b := range blocks {
+		r, err := parseCIDR(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(r.first) == net.IPv4len {
+			set.v4 = append(set.v4, r)
+		} else {
+			set.v6 = append(set.v6, r)
+		}
+	}
+	set.v4 = mergeIPRanges(set.v4)
+	set.v6 = mergeIPRanges(set.v6)
+	return set, nil
+}
+
+func mergeIPRanges(rs []ipRange) []ipRange {
+	if len(rs) == 0 {
+		return rs
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return bytes.Compare(rs[i].first, rs[j].first) < 0
+	})
+
+	merged := rs[:1]
+	for _, r := range rs[1:] {
+		cur := &merged[len(merged)-1]
+		if bytes.Compare(r.first, cur.last) <= 0 || isNextIP(cur.last, r.first) {
+			if bytes.Compare(r.last, cur.last) > 0 {
+				cur.last = r.last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// isNextIP reports whether next immediately follows ip.
+func isNextIP(ip, next net.IP) bool {
+	if len(ip) == net.IPv4len {
+		a := binary.BigEndian.Uint32(ip)
+		return a != ^uint32(0) && a+1 == binary.BigEndian.Uint32(next)
+	}
+	hi, lo := binary.BigEndian.Uint64(ip[:8]), binary.BigEndian.Uint64(ip[8:])
+	nhi, nlo := binary.BigEndian.Uint64(next[:8]), binary.BigEndian.Uint64(next[8:])
+	if lo == ^uint64(0) {
+		return hi != ^uint64(0) && hi+1 == nhi && nlo == 0
+	}
+	return hi == nhi && lo+1 == nlo
+}
+
+func (set *cidrSet) contains(ip net.IP) bool {
+	rs := set.v6
+	if len(ip) == net.IPv4len {
+		rs = set.v4
+	}
+	i := sort.Search(len(rs), func(i int) bool {
+		return bytes.Compare(rs[i].last, ip) >= 0
+	})
+	return i < len(rs) && bytes.Compare(rs[i].first, ip) <= 0
+}
+
+// cidrStrings converts a single CIDR string, or a list of them, into a
+// slice of strings. ok is false if any entry is not a string.
+func cidrStrings(i interface{}) ([]string, bool) {
+	switch v := i.(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		strs := make([]string, len(v))
+		for i := range v {
+			str, ok := v[i].(string)
+			if !ok {
+				return nil, false
+			}
+			strs[i] = str
+		}
+		return strs, true
+	default:
+		return nil, false
+	}
+}
+
+func buildIPInCIDROp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return falsef, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	contains := func(ip net.IP, set *cidrSet) interface{} {
+		if ip == nil {
+			return false
+		}
+		return set.contains(ip)
+	}
+
+	// A constant list of blocks is parsed once, and must be valid.
+	if rval, ok := constantArg(args[1]); ok {
+		blocks, ok := cidrStrings(rval)
+		if !ok {
+			return nil, fmt.Errorf("%s: CIDR blocks must be a string or list of strings, got %v", ipInCIDROp, rval)
+		}
+		set, err := newCIDRSet(blocks)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ipInCIDROp, err)
+		}
+		return func(ctx context.Context, data interface{}) interface{} {
+			ipstr, _ := lArg(ctx, data).(string)
+			return contains(parseIP(ipstr), set)
+		}, nil
+	}
+
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		ipstr, _ := lArg(ctx, data).(string)
+		ip := parseIP(ipstr)
+		if ip == nil {
+			return false
+		}
+		blocks, ok := cidrStrings(rArg(ctx, data))
+		if !ok {
+			return false
+		}
+		set, err := newCIDRSet(blocks)
+		if err != nil {
+			return false
+		}
+		return contains(ip, set)
+	}, nil
+}
+
+func buildIPVersionOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		ipstr, _ := lArg(ctx, data).(string)
+		switch ip := parseIP(ipstr); {
+		case ip == nil:
+			return nil
+		case len(ip) == net.IPv4len:
+			return float64(4)
+		default:
+			return float64(6)
+		}
+	}, nil
+}
@@ -0,0 +1,183 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIDRSet(t *testing.T) {
+	set, err := newCIDRSet([]string{
+		"10.0.0.0/8",
+		"192.168.1.0/25",
+		"192.168.1.128/25",
+		"172.16.5.4",
+		"2001:db8::/32",
+		"10.1.0.0/16",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, set.v4, 3, "adjacent and overlapping blocks are merged")
+	assert.Len(t, set.v6, 1)
+
+	tests := []struct {
+		ip     string
+		expect bool
+	}{
+		{"10.0.0.0", true},
+		{"10.255.255.255", true},
+		{"11.0.0.0", false},
+		{"9.255.255.255", false},
+		{"192.168.1.200", true},
+		{"192.168.2.0", false},
+		{"172.16.5.4", true},
+		{"172.16.5.5", false},
+		{"::ffff:10.1.2.3", true},
+		{"2001:db8:ffff::1", true},
+		{"2001:db9::1", false},
+		{"::1", false},
+	}
+
+	for _, st := range tests {
+		t.Run(st.ip, func(t *testing.T) {
+			ip := parseIP(st.ip)
+			assert.NotNil(t, ip)
+			assert.Equal(t, st.expect, set.contains(ip))
+		})
+	}
+}
+
+func TestIPOps(t *testing.T) {
+	type test struct {
+		name       string
+		rule       string
+		data       interface{}
+		expect     interface{}
+		compileErr string
+	}
+
+	data := map[string]interface{}{
+		"request": map[string]interface{}{
+			"ip":  "203.0.113.7",
+			"ip6": "2001:db8::7",
+			"bad": "not-an-ip",
+		},
+		"blocks": []interface{}{"203.0.113.0/24", "2001:db8::/32"},
+	}
+
+	tests := []test{
+		{
+			name:   "in-single",
+			rule:   `{"ip_in_cidr":[{"var":"request.ip"},"203.0.113.0/24"]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "in-list",
+			rule:   `{"ip_in_cidr":[{"var":"request.ip6"},["10.0.0.0/8","2001:db8::/32"]]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "not-in-list",
+			rule:   `{"ip_in_cidr":[{"var":"request.ip"},["10.0.0.0/8","2001:db8::/32"]]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "bad-ip",
+			rule:   `{"ip_in_cidr":[{"var":"request.bad"},"0.0.0.0/0"]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "missing-ip",
+			rule:   `{"ip_in_cidr":[{"var":"request.none"},"0.0.0.0/0"]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "dynamic-blocks",
+			rule:   `{"ip_in_cidr":[{"var":"request.ip6"},{"var":"blocks"}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "one-arg",
+			rule:   `{"ip_in_cidr":[{"var":"request.ip"}]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:       "bad-constant-block",
+			rule:       `{"ip_in_cidr":[{"var":"request.ip"},["10.0.0.0/8","10.0.0.0/33"]]}`,
+			compileErr: `ip_in_cidr: invalid CIDR block "10.0.0.0/33"`,
+		},
+		{
+			name:       "non-string-constant-block",
+			rule:       `{"ip_in_cidr":[{"var":"request.ip"},[1]]}`,
+			compileErr: `ip_in_cidr: CIDR blocks must be a string or list of strings, got [1]`,
+		},
+		{
+			name:   "version-4",
+			rule:   `{"ip_version":[{"var":"request.ip"}]}`,
+			data:   data,
+			expect: float64(4),
+		},
+		{
+			name:   "version-6",
+			rule:   `{"ip_version":{"var":"request.ip6"}}`,
+			data:   data,
+			expect: float64(6),
+		},
+		{
+			name:   "version-invalid",
+			rule:   `{"ip_version":{"var":"request.bad"}}`,
+			data:   data,
+			expect: nil,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				if st.compileErr != "" {
+					assert.EqualErrorf(t, err, st.compileErr, "compile error")
+					return
+				}
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
+
+func BenchmarkIPInCIDR(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	rule := `{"ip_in_cidr":[{"var":"ip"},["10.0.0.0/8","172.16.0.0/12","192.168.0.0/16","2001:db8::/32"]]}`
+	data := map[string]interface{}{"ip": "192.168.10.20"}
+
+	var c Clause
+	err := json.Unmarshal([]byte(rule), &c)
+	if err != nil {
+		b.Fatalf("unmarshal failed, %v", err)
+	}
+
+	cf, err := Compile(&c)
+	if err != nil {
+		b.Fatalf("compile failed, %v", err)
+	}
+	b.ResetTimer()
+	for i := b.N; i >= 0; i-- {
+		cf(ctx, data)
+	}
+}
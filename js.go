@@ -1,11 +1,14 @@
 package jsonlogic
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -19,7 +22,7 @@ func IsTrue(i interface{}) bool {
 	}
 	switch v := i.(type) {
 	case float64:
-		return v != 0
+		return v != 0 && !math.IsNaN(v)
 	case map[string]interface{}:
 		return true
 	case []interface{}:
@@ -29,50 +32,203 @@ func IsTrue(i interface{}) bool {
 	case bool:
 		return v
 	default:
+		if isNumber(v) {
+			f := toNumber(v)
+			return f != 0 && !math.IsNaN(f)
+		}
 		return true
 	}
 }
 
+// toNumber converts a value to a number, as per the JavaScript Number
+// function.
 func toNumber(i interface{}) float64 {
 	switch v := i.(type) {
 	case string:
-		tstr := strings.TrimSpace(v)
-		if tstr == "" {
-			return 0.0
-		}
-		f, err := strconv.ParseFloat(v, 64)
-		if err != nil {
-			return math.NaN()
-		}
-		return f
+		return stringToNumber(v)
 	case float64:
 		return v
+	case json.Number:
+		return toNumber(v.String())
 	case bool:
 		if v {
 			return 1.0
 		}
 		return 0.0
 	case []interface{}:
-		if len(v) == 0 {
-			return 0.0
-		}
-		if len(v) == 1 {
-			return toNumber(v[0])
-		}
-		return math.NaN()
+		return stringToNumber(toString(v))
 	case nil:
 		return 0.0
 	default:
+		if f, ok := goNumberToFloat(v); ok {
+			return f
+		}
 		return math.NaN()
 	}
 }
 
+// isJSSpace reports whether r is white space or a line terminator, as
+// trimmed by JavaScript when converting a string to a number.
+func isJSSpace(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', 0xa0, 0x1680, 0x2028, 0x2029,
+		0x202f, 0x205f, 0x3000, 0xfeff:
+		return true
+	}
+	return r >= 0x2000 && r <= 0x200a
+}
+
+// stringToNumber converts a string to a number as per JavaScript. Unlike
+// strconv.ParseFloat, surrounding white space is ignored, an empty string
+// is 0, "Infinity" is the only spelling of infinity, and integers may be
+// given in hex, octal or binary with a 0x, 0o or 0b prefix.
+func stringToNumber(s string) float64 {
+	s = strings.TrimFunc(s, isJSSpace)
+	switch s {
+	case "":
+		return 0
+	case "Infinity", "+Infinity":
+		return math.Inf(1)
+	case "-Infinity":
+		return math.Inf(-1)
+	}
+
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 0 {
+			n := new(big.Int)
+			for _, c := range s[2:] {
+				d, err := strconv.ParseUint(string(c), base, 8)
+				if err != nil {
+					return math.NaN()
+				}
+				n.Mul(n, big.NewInt(int64(base)))
+				n.Add(n, new(big.Int).SetUint64(d))
+			}
+			f, _ := new(big.Float).SetInt(n).Float64()
+			return f
+		}
+	}
+
+	if !isDecimalLiteral(s) {
+		return math.NaN()
+	}
+	// Out of range values are returned as ±Inf or 0, as per JavaScript.
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// isDecimalLiteral reports whether s is an optionally signed decimal
+// number, with an optional fraction and exponent, such as "-1.5e3", ".5" or
+// "5.".
+func isDecimalLiteral(s string) bool {
+	digits := func() int {
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		s = s[n:]
+		return n
+	}
+
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	n := digits()
+	if s != "" && s[0] == '.' {
+		s = s[1:]
+		n += digits()
+	}
+	if n == 0 {
+		return false
+	}
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s != "" && (s[0] == '+' || s[0] == '-') {
+			s = s[1:]
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return s == ""
+}
+
+// formatNumber formats a number as per JavaScript, using the shortest
+// representation that round trips, and exponent notation for very large
+// and very small numbers, e.g. 1e+21 and 1e-7.
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		// including -0
+		return "0"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// str is of the form d.ddde±dd, so the value is 0.dddd * 10^n
+	str := strconv.FormatFloat(f, 'e', -1, 64)
+	epos := strings.IndexByte(str, 'e')
+	digits := strings.Replace(str[:epos], ".", "", 1)
+	exp, _ := strconv.Atoi(str[epos+1:])
+	n := exp + 1
+	k := len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	expstr := "e+"
+	if n-1 < 0 {
+		expstr = "e-"
+	}
+	expstr += strconv.Itoa(abs(n - 1))
+	if k == 1 {
+		return sign + digits + expstr
+	}
+	return sign + digits[:1] + "." + digits[1:] + expstr
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// toString converts a value to a string, as per the JavaScript String
+// function.
 func toString(i interface{}) string {
 	switch v := i.(type) {
 	case string:
 		return v
 	case float64:
-		return strconv.FormatFloat(v, 'f', -1, 64)
+		return formatNumber(v)
+	case json.Number:
+		return v.String()
 	case bool:
 		if v {
 			return "true"
@@ -83,23 +239,118 @@ func toString(i interface{}) string {
 	case []interface{}:
 		var strs = make([]string, len(v))
 		for i := range v {
-			strs[i] = toString(v[i])
+			// null array elements are rendered as empty strings
+			if v[i] != nil {
+				strs[i] = toString(v[i])
+			}
 		}
 		return strings.Join(strs, ",")
+	case map[string]interface{}:
+		return "[object Object]"
 	default:
+		if str, ok := goNumberToString(v); ok {
+			return str
+		}
 		return fmt.Sprintf("%v", v)
 	}
 }
 
+// toPrimitive converts arrays and objects to strings, as JavaScript does
+// before comparing them with other values.
+func toPrimitive(i interface{}) interface{} {
+	switch i.(type) {
+	case []interface{}, map[string]interface{}:
+		return toString(i)
+	default:
+		return i
+	}
+}
+
+// compareStrings compares two strings by their UTF-16 code units, as per
+// JavaScript. This only differs from comparing the bytes of the strings
+// for characters outside the basic multilingual plane, which are encoded
+// as surrogate pairs, and so sort before U+E000 to U+FFFF.
+func compareStrings(l, r string) int {
+	for l != "" && r != "" {
+		lr, ln := utf8.DecodeRuneInString(l)
+		rr, rn := utf8.DecodeRuneInString(r)
+		if lr != rr {
+			lsup, rsup := lr > 0xffff, rr > 0xffff
+			switch {
+			case lsup && !rsup:
+				if rr >= 0xe000 {
+					return -1
+				}
+				return 1
+			case rsup && !lsup:
+				if lr >= 0xe000 {
+					return 1
+				}
+				return -1
+			case lr < rr:
+				return -1
+			default:
+				return 1
+			}
+		}
+		l, r = l[ln:], r[rn:]
+	}
+	switch {
+	case l == "" && r == "":
+		return 0
+	case l == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+// compareJS compares two values as per the JavaScript relational
+// operators, returning -1, 0 or 1. Arrays and objects are converted to
+// strings, two strings are compared lexically, and anything else is
+// compared numerically. ok is false if the comparison involves NaN, in
+// which case all comparisons are false.
+func compareJS(l, r interface{}) (int, bool) {
+	l, r = toPrimitive(l), toPrimitive(r)
+	lstr, lisstr := l.(string)
+	rstr, risstr := r.(string)
+	if lisstr && risstr {
+		return compareStrings(lstr, rstr), true
+	}
+	return compareNumbers(l, r)
+}
+
+// Compare compares two values as per the <, <=, > and >= operations,
+// returning -1, 0 or 1. ok is false if the values can not be ordered, in
+// which case all comparisons are false. Like IsTrue, ToNumber and
+// ToString, it is used by code generated with GenerateGo, and may be used
+// by custom operations that should coerce values as the built in ones do.
+func Compare(l, r interface{}) (c int, ok bool) {
+	return compareJS(l, r)
+}
+
+// ToNumber converts a value to a number as per the arithmetic operations,
+// which follow the JavaScript Number function. Values that are not
+// numbers, such as "abc", give NaN.
+func ToNumber(i interface{}) float64 {
+	return toNumber(i)
+}
+
+// ToString converts a value to a string as per the cat operation, which
+// follows the JavaScript String function.
+func ToString(i interface{}) string {
+	return toString(i)
+}
+
 // IsEqual is an exact equality check.
 func IsEqual(l, r interface{}) bool {
-	_, lisfloat := l.(float64)
+	lisnum := isNumber(l)
 	_, lisstr := l.(string)
 	_, lisbool := l.(bool)
 	lslice, lisslice := l.([]interface{})
 	lmap, lismap := l.(map[string]interface{})
 
-	_, risfloat := r.(float64)
+	risnum := isNumber(r)
 	_, risstr := r.(string)
 	_, risbool := r.(bool)
 	rslice, risslice := r.([]interface{})
@@ -121,9 +372,10 @@ func IsEqual(l, r interface{}) bool {
 		return lhdr.Cap == rhdr.Cap &&
 			lhdr.Len == rhdr.Len &&
 			lhdr.Data == rhdr.Data
+	case lisnum && risnum:
+		return numbersEqual(l, r)
 	case
 		lisbool && risbool,
-		lisfloat && risfloat,
 		lisstr && risstr:
 		return l == r
 	default:
@@ -134,13 +386,13 @@ func IsEqual(l, r interface{}) bool {
 // IsSoftEqual is an equality check that will
 // coerce values according to JavaScript rules.
 func IsSoftEqual(l, r interface{}) bool {
-	_, lisfloat := l.(float64)
+	lisnum := isNumber(l)
 	_, lisstr := l.(string)
 	_, lisbool := l.(bool)
 	lslice, lisslice := l.([]interface{})
 	lmap, lismap := l.(map[string]interface{})
 
-	_, risfloat := r.(float64)
+	risnum := isNumber(r)
 	_, risstr := r.(string)
 	_, risbool := r.(bool)
 	rslice, risslice := r.([]interface{})
@@ -162,20 +414,20 @@ func IsSoftEqual(l, r interface{}) bool {
 		return lhdr.Cap == rhdr.Cap &&
 			lhdr.Len == rhdr.Len &&
 			lhdr.Data == rhdr.Data
-	case lisslice || risslice:
-		if lisslice {
-			return IsSoftEqual(toString(l), r)
-		}
-		return IsSoftEqual(l, toString(r))
-	case lismap || rismap:
+	case lisslice && rismap, lismap && risslice:
+		// distinct objects
 		return false
+	case lisslice || risslice || lismap || rismap:
+		// compared as per their string representations
+		return IsSoftEqual(toPrimitive(l), toPrimitive(r))
+	case lisnum && risnum:
+		return numbersEqual(l, r)
 	case
 		lisbool && risbool,
-		lisfloat && risfloat,
 		lisstr && risstr:
 		return l == r
 	default:
-		return toNumber(l) == toNumber(r)
+		return numbersEqual(l, r)
 	}
 }
 
@@ -183,13 +435,13 @@ func IsSoftEqual(l, r interface{}) bool {
 // coerce values according to JavaScript rules, and
 // compare map and array content..
 func IsDeepEqual(l, r interface{}) bool {
-	_, lisfloat := l.(float64)
+	lisnum := isNumber(l)
 	_, lisstr := l.(string)
 	_, lisbool := l.(bool)
 	lslice, lisslice := l.([]interface{})
 	lmap, lismap := l.(map[string]interface{})
 
-	_, risfloat := r.(float64)
+	risnum := isNumber(r)
 	_, risstr := r.(string)
 	_, risbool := r.(bool)
 	rslice, risslice := r.([]interface{})
@@ -235,12 +487,13 @@ func IsDeepEqual(l, r interface{}) bool {
 		return IsSoftEqual(l, toString(r))
 	case lismap || rismap:
 		return false
+	case lisnum && risnum:
+		return numbersEqual(l, r)
 	case
 		lisbool && risbool,
-		lisfloat && risfloat,
 		lisstr && risstr:
 		return l == r
 	default:
-		return toNumber(l) == toNumber(r)
+		return numbersEqual(l, r)
 	}
 }
@@ -0,0 +1,127 @@
+package jsonlogic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Named value operations
+	letOp = "let"
+	valOp = "val"
+)
+
+type letFrameKey struct{}
+
+// letFrame holds the values bound by one evaluation of a let operation. It
+// is carried in the context, rather than in the data, so that bindings
+// remain visible inside map, filter, reduce and the other operations that
+// replace the data for their inner expressions.
+type letFrame struct {
+	names  []string
+	values []interface{}
+	parent *letFrame
+}
+
+// lookup finds the innermost binding for ref. If ref is a dotted reference
+// whose first part(s) name a binding, the remainder is resolved against the
+// bound value, as per DottedRef.
+func (f *letFrame) lookup(ref string) (interface{}, bool) {
+	for ; f != nil; f = f.parent {
+		for i := len(f.values) - 1; i >= 0; i-- {
+			name := f.names[i]
+			switch {
+			case ref == name:
+				return f.values[i], true
+			case strings.HasPrefix(ref, name+"."):
+				return DottedRef(f.values[i], ref[len(name)+1:]), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// buildLetOp builds the let operation. It takes pairs of names and
+// expressions followed by a body, e.g.
+//
+//	{"let": ["total", {"reduce": [...]}, {">": [{"val": "total"}, 100]}]}
+//
+// Each expression is evaluated once, in order, and may refer to the names
+// bound before it. The body is evaluated with all names bound.
+func buildLetOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+	if len(args)%2 != 1 {
+		return nil, fmt.Errorf("%s: expected pairs of names and values followed by a body", letOp)
+	}
+
+	n := len(args) / 2
+	names := make([]string, n)
+	valueArgs := make([]ClauseFunc, n)
+	for i := 0; i < n; i++ {
+		nameVal, _ := constantArg(args[i*2])
+		name, ok := nameVal.(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s: binding names must be non-empty strings", letOp)
+		}
+		names[i] = name
+
+		valueArg, err := BuildArgFunc(args[i*2+1], ops)
+		if err != nil {
+			return nil, err
+		}
+		valueArgs[i] = valueArg
+	}
+
+	bodyArg, err := BuildArgFunc(args[len(args)-1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		parent, _ := ctx.Value(letFrameKey{}).(*letFrame)
+		frame := &letFrame{
+			names:  names,
+			values: make([]interface{}, 0, n),
+			parent: parent,
+		}
+		lctx := context.WithValue(ctx, letFrameKey{}, frame)
+
+		for _, va := range valueArgs {
+			frame.values = append(frame.values, va(lctx, data))
+		}
+		return bodyArg(lctx, data)
+	}, nil
+}
+
+// buildValOp builds the val operation, which looks up a name bound by an
+// enclosing let, with an optional default, e.g. {"val": ["total", 0]}. It
+// never looks at the data, so names can not collide with var lookups.
+func buildValOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return nullf, nil
+	}
+
+	refArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultArg := nullf
+	if len(args) >= 2 {
+		if defaultArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		frame, _ := ctx.Value(letFrameKey{}).(*letFrame)
+		ref, _ := refArg(ctx, data).(string)
+		if v, ok := frame.lookup(ref); ok && v != nil {
+			return v
+		}
+		return defaultArg(ctx, data)
+	}, nil
+}
@@ -0,0 +1,169 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLetOps(t *testing.T) {
+	type test struct {
+		name       string
+		rule       string
+		data       interface{}
+		expect     interface{}
+		compileErr string
+	}
+
+	var data interface{}
+	err := json.Unmarshal([]byte(`{
+		"orders": [{"value": 10}, {"value": 30}, {"value": 20}],
+		"total": "from data",
+		"limit": 15
+	}`), &data)
+	assert.NoError(t, err)
+
+	total := `{"reduce":[{"var":"orders"},{"+":[{"var":"accumulator"},{"var":"current.value"}]},0]}`
+
+	tests := []test{
+		{
+			name:   "simple",
+			rule:   `{"let":["x",1,{"+":[{"val":"x"},{"val":"x"}]}]}`,
+			expect: float64(2),
+		},
+		{
+			name:   "expensive-expression",
+			rule:   `{"let":["total",` + total + `,{"if":[{">":[{"val":"total"},50]},{"val":"total"},0]}]}`,
+			data:   data,
+			expect: float64(60),
+		},
+		{
+			name:   "no-collision-with-var",
+			rule:   `{"let":["total",` + total + `,{"cat":[{"var":"total"}," ",{"val":"total"}]}]}`,
+			data:   data,
+			expect: "from data 60",
+		},
+		{
+			name:   "sequential",
+			rule:   `{"let":["a",2,"b",{"*":[{"val":"a"},3]},{"-":[{"val":"b"},{"val":"a"}]}]}`,
+			expect: float64(4),
+		},
+		{
+			name:   "shadowing",
+			rule:   `{"let":["a",1,{"let":["a",2,{"val":"a"}]}]}`,
+			expect: float64(2),
+		},
+		{
+			name:   "outer-binding",
+			rule:   `{"let":["a",1,{"let":["b",2,{"+":[{"val":"a"},{"val":"b"}]}]}]}`,
+			expect: float64(3),
+		},
+		{
+			name:   "dotted",
+			rule:   `{"let":["o",{"var":"orders"},{"val":"o.1.value"}]}`,
+			data:   data,
+			expect: float64(30),
+		},
+		{
+			name:   "default",
+			rule:   `{"val":["missing","dflt"]}`,
+			expect: "dflt",
+		},
+		{
+			name:   "unbound",
+			rule:   `{"let":["a",1,{"val":"b"}]}`,
+			expect: nil,
+		},
+		{
+			name:   "inside-map",
+			rule:   `{"let":["limit",{"var":"limit"},{"map":[{"var":"orders"},{">":[{"var":"value"},{"val":"limit"}]}]}]}`,
+			data:   data,
+			expect: []interface{}{false, true, true},
+		},
+		{
+			name:   "inside-filter",
+			rule:   `{"let":["min",15,{"filter":[{"var":"orders"},{">=":[{"var":"value"},{"val":"min"}]}]}]}`,
+			data:   data,
+			expect: []interface{}{map[string]interface{}{"value": float64(30)}, map[string]interface{}{"value": float64(20)}},
+		},
+		{
+			name:   "inside-reduce",
+			rule:   `{"let":["w",2,{"reduce":[{"var":"orders"},{"+":[{"var":"accumulator"},{"*":[{"val":"w"},{"var":"current.value"}]}]},0]}]}`,
+			data:   data,
+			expect: float64(120),
+		},
+		{
+			name:   "let-per-element",
+			rule:   `{"map":[{"var":"orders"},{"let":["v",{"var":"value"},{"*":[{"val":"v"},{"val":"v"}]}]}]}`,
+			data:   data,
+			expect: []interface{}{float64(100), float64(900), float64(400)},
+		},
+		{
+			name:   "body-only",
+			rule:   `{"let":[{"var":"limit"}]}`,
+			data:   data,
+			expect: float64(15),
+		},
+		{
+			name:       "missing-body",
+			rule:       `{"let":["a",1]}`,
+			compileErr: "let: expected pairs of names and values followed by a body",
+		},
+		{
+			name:       "non-string-name",
+			rule:       `{"let":[1,1,{"val":"a"}]}`,
+			compileErr: "let: binding names must be non-empty strings",
+		},
+		{
+			name:       "computed-name",
+			rule:       `{"let":[{"var":"a"},1,{"val":"a"}]}`,
+			compileErr: "let: binding names must be non-empty strings",
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				if st.compileErr != "" {
+					assert.EqualErrorf(t, err, st.compileErr, "compile error")
+					return
+				}
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
+
+func TestLetEvaluatesOnce(t *testing.T) {
+	calls := 0
+	ops := OpsSet{}
+	for k, v := range DefaultOps {
+		ops[k] = v
+	}
+	ops["counted"] = func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		return func(ctx context.Context, data interface{}) interface{} {
+			calls++
+			return float64(calls)
+		}, nil
+	}
+
+	var c Clause
+	err := json.Unmarshal([]byte(`{"let":["c",{"counted":[]},[{"val":"c"},{"val":"c"},{"val":"c"}]]}`), &c)
+	assert.NoError(t, err)
+
+	cf, err := ops.Compile(&c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []interface{}{float64(1), float64(1), float64(1)}, cf(context.Background(), nil))
+	assert.Equal(t, 1, calls)
+}
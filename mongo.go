@@ -0,0 +1,379 @@
+package jsonlogic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMongoUnsupported is wrapped by the errors ToMongo and FromMongo return
+// for rules and queries that can not be converted.
+var ErrMongoUnsupported = errors.New("can not be converted to or from a mongo query")
+
+var mongoCompareOps = map[string]string{
+	equalOp:         "$eq",
+	equalThreeOp:    "$eq",
+	notEqualOp:      "$ne",
+	notEqualThreeOp: "$ne",
+	lessOp:          "$lt",
+	lessEqOp:        "$lte",
+	greaterOp:       "$gt",
+	greaterEqOp:     "$gte",
+}
+
+// fromMongoCompareOps maps query operators to the operations they are
+// converted back to. Mongo comparisons do not coerce types, so equality is
+// strict.
+var fromMongoCompareOps = map[string]string{
+	"$eq":  equalThreeOp,
+	"$ne":  notEqualThreeOp,
+	"$lt":  lessOp,
+	"$lte": lessEqOp,
+	"$gt":  greaterOp,
+	"$gte": greaterEqOp,
+}
+
+// flippedCompareOps gives the operation to use when the operands of a
+// comparison are swapped.
+var flippedCompareOps = map[string]string{
+	lessOp:      greaterOp,
+	lessEqOp:    greaterEqOp,
+	greaterOp:   lessOp,
+	greaterEqOp: lessEqOp,
+}
+
+func mongoUnsupported(what string) error {
+	return fmt.Errorf("%s %w", what, ErrMongoUnsupported)
+}
+
+// mongoMatchNone is a query that matches no documents.
+func mongoMatchNone() map[string]interface{} {
+	return map[string]interface{}{"$nor": []interface{}{map[string]interface{}{}}}
+}
+
+// ToMongo converts a rule into a MongoDB query document. Dotted var paths
+// are used as field paths, which Mongo resolves as DottedRef does,
+// including numeric array indexes. The supported operations are and, or,
+// !, !!, the equality and comparison operations between a var and a
+// constant other than an array or object, in on a var and a constant
+// array, and missing. Errors for
+// anything else wrap ErrMongoUnsupported.
+//
+// Queries follow Mongo semantics. In particular, comparisons do not coerce
+// types, a field holding an array matches if any element matches, and
+// missing is converted to $exists: false, which does not match fields that
+// are present with a null value.
+func ToMongo(c *Clause) (map[string]interface{}, error) {
+	return mongoQuery(Argument{Clause: c})
+}
+
+func mongoQuery(arg Argument) (map[string]interface{}, error) {
+	if v, ok := constantArg(arg); ok {
+		if IsTrue(v) {
+			return map[string]interface{}{}, nil
+		}
+		return mongoMatchNone(), nil
+	}
+
+	op, args := arg.Clause.Operator.Name, arg.Clause.Arguments
+	switch op {
+	case andOp, orOp:
+		if len(args) == 0 {
+			return mongoMatchNone(), nil
+		}
+		subs := make([]interface{}, len(args))
+		for i, a := range args {
+			sub, err := mongoQuery(a)
+			if err != nil {
+				return nil, err
+			}
+			subs[i] = sub
+		}
+		return map[string]interface{}{"$" + op: subs}, nil
+	case negateOp:
+		if len(args) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return mongoNegate(args[0])
+	case doubleNegateOp:
+		if len(args) == 0 {
+			return mongoMatchNone(), nil
+		}
+		return mongoQuery(args[0])
+	case equalOp, equalThreeOp, notEqualOp, notEqualThreeOp,
+		lessOp, lessEqOp, greaterOp, greaterEqOp:
+		return mongoCompare(op, args)
+	case inOp:
+		path, vals, err := mongoIn(args)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{path: map[string]interface{}{"$in": vals}}, nil
+	case missingOp:
+		paths, ok := missingPaths(args)
+		if !ok {
+			return nil, mongoUnsupported("missing with computed paths")
+		}
+		subs := make([]interface{}, len(paths))
+		for i, p := range paths {
+			if p == "" {
+				return nil, mongoUnsupported("missing of the whole data")
+			}
+			subs[i] = map[string]interface{}{p: map[string]interface{}{"$exists": false}}
+		}
+		switch len(subs) {
+		case 0:
+			return mongoMatchNone(), nil
+		case 1:
+			return subs[0].(map[string]interface{}), nil
+		default:
+			return map[string]interface{}{"$or": subs}, nil
+		}
+	default:
+		return nil, mongoUnsupported(fmt.Sprintf("operation %q", op))
+	}
+}
+
+// mongoNegate converts the negation of arg, using $nin and $exists in
+// place of $nor where possible.
+func mongoNegate(arg Argument) (map[string]interface{}, error) {
+	if arg.Clause != nil {
+		switch args := arg.Clause.Arguments; arg.Clause.Operator.Name {
+		case inOp:
+			path, vals, err := mongoIn(args)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{path: map[string]interface{}{"$nin": vals}}, nil
+		case missingOp:
+			if paths, ok := missingPaths(args); ok && len(paths) == 1 && paths[0] != "" {
+				return map[string]interface{}{paths[0]: map[string]interface{}{"$exists": true}}, nil
+			}
+		}
+	}
+
+	sub, err := mongoQuery(arg)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"$nor": []interface{}{sub}}, nil
+}
+
+// constantVarPath returns the path of a var argument, which must be
+// constant, and have no default.
+func constantVarPath(arg Argument) (string, bool) {
+	if arg.Clause == nil || arg.Clause.Operator.Name != varOp || len(arg.Clause.Arguments) != 1 {
+		return "", false
+	}
+	path, ok := constantPath(arg.Clause.Arguments[0])
+	return path, ok && path != ""
+}
+
+func mongoCompare(op string, args Arguments) (map[string]interface{}, error) {
+	if len(args) > 2 && (op == lessOp || op == lessEqOp) {
+		// A between test, lower < var < upper
+		lower, err := mongoCompare(op, args[:2])
+		if err != nil {
+			return nil, err
+		}
+		upper, err := mongoCompare(op, args[1:3])
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"$and": []interface{}{lower, upper}}, nil
+	}
+	if len(args) != 2 {
+		return nil, mongoUnsupported(fmt.Sprintf("operation %q with %d arguments", op, len(args)))
+	}
+
+	mop := mongoCompareOps[op]
+	path, ok := constantVarPath(args[0])
+	val, isconst := constantArg(args[1])
+	if !ok || !isconst {
+		// Try the var on the right
+		path, ok = constantVarPath(args[1])
+		val, isconst = constantArg(args[0])
+		if flipped, isflipped := flippedCompareOps[op]; isflipped {
+			mop = mongoCompareOps[flipped]
+		}
+	}
+	if !ok || !isconst {
+		return nil, mongoUnsupported(fmt.Sprintf("operation %q other than between a var and a constant", op))
+	}
+	if err := checkMongoScalar(fmt.Sprintf("operation %q", op), val); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{path: map[string]interface{}{mop: val}}, nil
+}
+
+func mongoIn(args Arguments) (string, []interface{}, error) {
+	if len(args) == 2 {
+		path, ok := constantVarPath(args[0])
+		v, isconst := constantArg(args[1])
+		vals, isslice := v.([]interface{})
+		if ok && isconst && isslice {
+			return path, vals, nil
+		}
+	}
+	return "", nil, mongoUnsupported("in other than of a var in a constant array")
+}
+
+// FromMongo converts a MongoDB query document to a rule. The supported
+// query operators are $and, $or, $nor, $eq, $ne, $gt, $gte, $lt, $lte,
+// $in, $nin and $exists, along with implicit equality. Fields are
+// converted to vars, and equality to ===, as Mongo does not coerce types.
+// Comparisons with documents or arrays, which Mongo matches by content or
+// by element, are not supported. Errors for anything else wrap
+// ErrMongoUnsupported.
+func FromMongo(q map[string]interface{}) (*Clause, error) {
+	arg, err := fromMongoQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if arg.Clause != nil {
+		return arg.Clause, nil
+	}
+	return &Clause{Arguments: Arguments{arg}}, nil
+}
+
+func opArg(op string, args ...Argument) Argument {
+	return Argument{Clause: &Clause{Operator: Operator{Name: op}, Arguments: args}}
+}
+
+func varArg(path string) Argument {
+	return opArg(varOp, Argument{Value: path})
+}
+
+// andArgs combines arguments with and, if there is more than one.
+func andArgs(args []Argument) Argument {
+	switch len(args) {
+	case 0:
+		return Argument{Value: true}
+	case 1:
+		return args[0]
+	default:
+		return opArg(andOp, args...)
+	}
+}
+
+func fromMongoQuery(q map[string]interface{}) (Argument, error) {
+	var parts []Argument
+	for _, k := range sortedKeys(q) {
+		v := q[k]
+		switch {
+		case k == "$and" || k == "$or" || k == "$nor":
+			subqs, ok := v.([]interface{})
+			if !ok || len(subqs) == 0 {
+				return Argument{}, mongoUnsupported(k + " without an array of queries")
+			}
+			subs := make([]Argument, len(subqs))
+			for i, subq := range subqs {
+				m, ok := subq.(map[string]interface{})
+				if !ok {
+					return Argument{}, mongoUnsupported(k + " without an array of queries")
+				}
+				sub, err := fromMongoQuery(m)
+				if err != nil {
+					return Argument{}, err
+				}
+				subs[i] = sub
+			}
+			switch {
+			case k == "$and":
+				parts = append(parts, opArg(andOp, subs...))
+			case k == "$or":
+				parts = append(parts, opArg(orOp, subs...))
+			case len(subs) == 1:
+				parts = append(parts, opArg(negateOp, subs[0]))
+			default:
+				parts = append(parts, opArg(negateOp, opArg(orOp, subs...)))
+			}
+		case strings.HasPrefix(k, "$"):
+			return Argument{}, mongoUnsupported(fmt.Sprintf("query operator %q", k))
+		default:
+			part, err := fromMongoField(k, v)
+			if err != nil {
+				return Argument{}, err
+			}
+			parts = append(parts, part)
+		}
+	}
+	return andArgs(parts), nil
+}
+
+// isMongoOperators reports whether m is a document of query operators, such
+// as {"$gt": 1}, rather than a document to match.
+func isMongoOperators(m map[string]interface{}) (bool, error) {
+	ops := 0
+	for k := range m {
+		if strings.HasPrefix(k, "$") {
+			ops++
+		}
+	}
+	if ops != 0 && ops != len(m) {
+		return false, mongoUnsupported("document mixing query operators and fields")
+	}
+	return ops != 0, nil
+}
+
+// checkMongoScalar returns an error if v, compared with a field by op, is a
+// document or array. Mongo compares those by content, and matches arrays
+// against their elements, but === compares them by identity.
+func checkMongoScalar(op string, v interface{}) error {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return mongoUnsupported(op + " with a document or array")
+	}
+	return nil
+}
+
+func fromMongoField(path string, v interface{}) (Argument, error) {
+	m, ok := v.(map[string]interface{})
+	if ok {
+		var err error
+		if ok, err = isMongoOperators(m); err != nil {
+			return Argument{}, err
+		}
+	}
+	if !ok {
+		// Implicit equality.
+		if err := checkMongoScalar("equality", v); err != nil {
+			return Argument{}, err
+		}
+		return opArg(equalThreeOp, varArg(path), Argument{Value: v}), nil
+	}
+
+	var parts []Argument
+	for _, k := range sortedKeys(m) {
+		val := m[k]
+		if op, ok := fromMongoCompareOps[k]; ok {
+			if err := checkMongoScalar(k, val); err != nil {
+				return Argument{}, err
+			}
+			parts = append(parts, opArg(op, varArg(path), Argument{Value: val}))
+			continue
+		}
+
+		switch k {
+		case "$in", "$nin":
+			if _, ok := val.([]interface{}); !ok {
+				return Argument{}, mongoUnsupported(k + " without an array")
+			}
+			in := opArg(inOp, varArg(path), Argument{Value: val})
+			if k == "$nin" {
+				in = opArg(negateOp, in)
+			}
+			parts = append(parts, in)
+		case "$exists":
+			missing := opArg(missingOp, Argument{Value: path})
+			if IsTrue(val) {
+				missing = opArg(negateOp, missing)
+			}
+			parts = append(parts, missing)
+		default:
+			return Argument{}, mongoUnsupported(fmt.Sprintf("query operator %q", k))
+		}
+	}
+	return andArgs(parts), nil
+}
@@ -0,0 +1,418 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMongo(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		expect string
+		err    string
+	}
+
+	tests := []test{
+		{
+			name:   "equal",
+			rule:   `{"==":[{"var":"country"},"GB"]}`,
+			expect: `{"country":{"$eq":"GB"}}`,
+		},
+		{
+			name:   "dotted-path",
+			rule:   `{"===":[{"var":"user.address.0"},"x"]}`,
+			expect: `{"user.address.0":{"$eq":"x"}}`,
+		},
+		{
+			name:   "constant-first",
+			rule:   `{"<":[18,{"var":"age"}]}`,
+			expect: `{"age":{"$gt":18}}`,
+		},
+		{
+			name:   "not-equal",
+			rule:   `{"!=":["free",{"var":"plan"}]}`,
+			expect: `{"plan":{"$ne":"free"}}`,
+		},
+		{
+			name:   "between",
+			rule:   `{"<=":[1,{"var":"n"},10]}`,
+			expect: `{"$and":[{"n":{"$gte":1}},{"n":{"$lte":10}}]}`,
+		},
+		{
+			name:   "and-or",
+			rule:   `{"and":[{">=":[{"var":"age"},18]},{"or":[{"==":[{"var":"a"},1]},{"==":[{"var":"b"},null]}]}]}`,
+			expect: `{"$and":[{"age":{"$gte":18}},{"$or":[{"a":{"$eq":1}},{"b":{"$eq":null}}]}]}`,
+		},
+		{
+			name:   "in",
+			rule:   `{"in":[{"var":"country"},["GB","FR"]]}`,
+			expect: `{"country":{"$in":["GB","FR"]}}`,
+		},
+		{
+			name:   "not-in",
+			rule:   `{"!":{"in":[{"var":"country"},["GB","FR"]]}}`,
+			expect: `{"country":{"$nin":["GB","FR"]}}`,
+		},
+		{
+			name:   "missing",
+			rule:   `{"missing":["a","b"]}`,
+			expect: `{"$or":[{"a":{"$exists":false}},{"b":{"$exists":false}}]}`,
+		},
+		{
+			name:   "not-missing",
+			rule:   `{"!":{"missing":"a"}}`,
+			expect: `{"a":{"$exists":true}}`,
+		},
+		{
+			name:   "not",
+			rule:   `{"!":{">":[{"var":"a"},1]}}`,
+			expect: `{"$nor":[{"a":{"$gt":1}}]}`,
+		},
+		{
+			name:   "double-not",
+			rule:   `{"!!":{">":[{"var":"a"},1]}}`,
+			expect: `{"a":{"$gt":1}}`,
+		},
+		{
+			name:   "true",
+			rule:   `true`,
+			expect: `{}`,
+		},
+		{
+			name:   "false",
+			rule:   `false`,
+			expect: `{"$nor":[{}]}`,
+		},
+		{
+			name: "two-vars",
+			rule: `{"==":[{"var":"a"},{"var":"b"}]}`,
+			err:  `operation "==" other than between a var and a constant can not be converted to or from a mongo query`,
+		},
+		{
+			name: "var-default",
+			rule: `{"==":[{"var":["a",1]},1]}`,
+			err:  `operation "==" other than between a var and a constant can not be converted to or from a mongo query`,
+		},
+		{
+			name: "array-equal",
+			rule: `{"===":[{"var":"a"},[1,2]]}`,
+			err:  `operation "===" with a document or array can not be converted to or from a mongo query`,
+		},
+		{
+			name: "array-compare",
+			rule: `{"<":[[1],{"var":"a"}]}`,
+			err:  `operation "<" with a document or array can not be converted to or from a mongo query`,
+		},
+		{
+			name: "in-string",
+			rule: `{"in":["x",{"var":"a"}]}`,
+			err:  `in other than of a var in a constant array can not be converted to or from a mongo query`,
+		},
+		{
+			name: "unsupported",
+			rule: `{"some":[{"var":"a"},true]}`,
+			err:  `operation "some" can not be converted to or from a mongo query`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				q, err := ToMongo(&c)
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					assert.True(t, errors.Is(err, ErrMongoUnsupported))
+					return
+				}
+				assert.NoError(t, err)
+
+				bs, err := json.Marshal(q)
+				assert.NoError(t, err)
+				assert.JSONEq(t, st.expect, string(bs))
+			})
+		})
+	}
+
+	// Object constants can only be built, as in JSON they are operations.
+	c := opArg(notEqualThreeOp, varArg("a"), Argument{Value: map[string]interface{}{"b": 1.0}}).Clause
+	_, err := ToMongo(c)
+	assert.EqualError(t, err, `operation "!==" with a document or array can not be converted to or from a mongo query`)
+}
+
+func TestFromMongo(t *testing.T) {
+	type test struct {
+		name   string
+		query  string
+		expect string
+		err    string
+	}
+
+	tests := []test{
+		{
+			name:   "implicit-equal",
+			query:  `{"country":"GB"}`,
+			expect: `{"===":[{"var":["country"]},"GB"]}`,
+		},
+		{
+			name:   "implicit-and",
+			query:  `{"b":2,"a":{"$gt":1,"$lte":5}}`,
+			expect: `{"and":[{"and":[{">":[{"var":["a"]},1]},{"<=":[{"var":["a"]},5]}]},{"===":[{"var":["b"]},2]}]}`,
+		},
+		{
+			name:   "or",
+			query:  `{"$or":[{"a":{"$ne":1}},{"b.c":{"$in":[1,2]}}]}`,
+			expect: `{"or":[{"!==":[{"var":["a"]},1]},{"in":[{"var":["b.c"]},[1,2]]}]}`,
+		},
+		{
+			name:   "nor",
+			query:  `{"$nor":[{"a":1},{"b":{"$nin":["x"]}}]}`,
+			expect: `{"!":[{"or":[{"===":[{"var":["a"]},1]},{"!":[{"in":[{"var":["b"]},["x"]]}]}]}]}`,
+		},
+		{
+			name:   "exists",
+			query:  `{"a":{"$exists":true},"b":{"$exists":false}}`,
+			expect: `{"and":[{"!":[{"missing":["a"]}]},{"missing":["b"]}]}`,
+		},
+		{
+			name:   "empty",
+			query:  `{}`,
+			expect: `true`,
+		},
+		{
+			name:  "unsupported-operator",
+			query: `{"a":{"$regex":"^x"}}`,
+			err:   `query operator "$regex" can not be converted to or from a mongo query`,
+		},
+		{
+			name:  "unsupported-top-level-operator",
+			query: `{"$where":"true"}`,
+			err:   `query operator "$where" can not be converted to or from a mongo query`,
+		},
+		{
+			name:  "mixed-document",
+			query: `{"a":{"$gt":1,"b":2}}`,
+			err:   `document mixing query operators and fields can not be converted to or from a mongo query`,
+		},
+		{
+			name:  "document-equal",
+			query: `{"a":{"b":1}}`,
+			err:   `equality with a document or array can not be converted to or from a mongo query`,
+		},
+		{
+			name:  "array-equal",
+			query: `{"a":[1,2]}`,
+			err:   `equality with a document or array can not be converted to or from a mongo query`,
+		},
+		{
+			name:  "array-ne",
+			query: `{"a":{"$ne":[1]}}`,
+			err:   `$ne with a document or array can not be converted to or from a mongo query`,
+		},
+		{
+			name:  "empty-and",
+			query: `{"$and":[]}`,
+			err:   `$and without an array of queries can not be converted to or from a mongo query`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var q map[string]interface{}
+				err := json.Unmarshal([]byte(st.query), &q)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				c, err := FromMongo(q)
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					return
+				}
+				assert.NoError(t, err)
+
+				bs, err := json.Marshal(c)
+				assert.NoError(t, err)
+				assert.JSONEq(t, st.expect, string(bs))
+			})
+		})
+	}
+}
+
+var (
+	mongoTestPaths  = []string{"a", "b", "c.d", "items.0"}
+	mongoTestValues = []interface{}{1.0, 2.0, 3.0, "x", "y", nil}
+)
+
+func randomMongoValue(r *rand.Rand) interface{} {
+	return mongoTestValues[r.Intn(len(mongoTestValues))]
+}
+
+func randomMongoVar(r *rand.Rand) Argument {
+	return varArg(mongoTestPaths[r.Intn(len(mongoTestPaths))])
+}
+
+// randomMongoRule generates a random rule from the subset of operations
+// that ToMongo supports.
+func randomMongoRule(r *rand.Rand, depth int) Argument {
+	n := 8
+	if depth <= 0 {
+		n = 6
+	}
+	switch r.Intn(n) {
+	case 0:
+		ops := []string{equalOp, equalThreeOp, notEqualOp, notEqualThreeOp, lessOp, lessEqOp, greaterOp, greaterEqOp}
+		args := []Argument{randomMongoVar(r), {Value: randomMongoValue(r)}}
+		if r.Intn(2) == 0 {
+			args[0], args[1] = args[1], args[0]
+		}
+		return opArg(ops[r.Intn(len(ops))], args...)
+	case 1:
+		ops := []string{lessOp, lessEqOp}
+		return opArg(ops[r.Intn(len(ops))], Argument{Value: randomMongoValue(r)}, randomMongoVar(r), Argument{Value: randomMongoValue(r)})
+	case 2:
+		vals := make([]interface{}, r.Intn(3))
+		for i := range vals {
+			vals[i] = randomMongoValue(r)
+		}
+		in := opArg(inOp, randomMongoVar(r), Argument{Value: vals})
+		if r.Intn(2) == 0 {
+			in = opArg(negateOp, in)
+		}
+		return in
+	case 3:
+		paths := make([]interface{}, 1+r.Intn(2))
+		for i := range paths {
+			paths[i] = mongoTestPaths[r.Intn(len(mongoTestPaths))]
+		}
+		missing := opArg(missingOp, Argument{Value: paths})
+		if r.Intn(2) == 0 {
+			missing = opArg(negateOp, missing)
+		}
+		return missing
+	case 4:
+		return Argument{Value: r.Intn(2) == 0}
+	case 5:
+		return opArg(equalOp, randomMongoVar(r), Argument{Value: randomMongoValue(r)})
+	case 6:
+		ops := []string{negateOp, doubleNegateOp}
+		return opArg(ops[r.Intn(len(ops))], randomMongoRule(r, depth-1))
+	default:
+		ops := []string{andOp, orOp}
+		args := make([]Argument, 1+r.Intn(3))
+		for i := range args {
+			args[i] = randomMongoRule(r, depth-1)
+		}
+		return opArg(ops[r.Intn(len(ops))], args...)
+	}
+}
+
+func randomMongoData(r *rand.Rand) interface{} {
+	data := map[string]interface{}{}
+	if r.Intn(4) != 0 {
+		data["a"] = randomMongoValue(r)
+	}
+	if r.Intn(4) != 0 {
+		data["b"] = randomMongoValue(r)
+	}
+	if r.Intn(4) != 0 {
+		data["c"] = map[string]interface{}{"d": randomMongoValue(r)}
+	}
+	if r.Intn(4) != 0 {
+		items := make([]interface{}, r.Intn(3))
+		for i := range items {
+			items[i] = randomMongoValue(r)
+		}
+		data["items"] = items
+	}
+	return data
+}
+
+func TestFromMongoEval(t *testing.T) {
+	type test struct {
+		name   string
+		query  string
+		data   string
+		expect bool
+	}
+
+	tests := []test{
+		{name: "equal", query: `{"a":"x"}`, data: `{"a":"x"}`, expect: true},
+		{name: "equal-type", query: `{"a":1}`, data: `{"a":"1"}`, expect: false},
+		{name: "nested-path", query: `{"a.b":1}`, data: `{"a":{"b":1}}`, expect: true},
+		{name: "range", query: `{"a":{"$gt":1,"$lte":5}}`, data: `{"a":5}`, expect: true},
+		{name: "range-outside", query: `{"a":{"$gt":1,"$lte":5}}`, data: `{"a":6}`, expect: false},
+		{name: "in", query: `{"a":{"$in":["x","y"]}}`, data: `{"a":"y"}`, expect: true},
+		{name: "nin", query: `{"a":{"$nin":["x","y"]}}`, data: `{"a":"y"}`, expect: false},
+		{name: "exists", query: `{"a":{"$exists":true}}`, data: `{"b":1}`, expect: false},
+		{name: "or", query: `{"$or":[{"a":1},{"b":2}]}`, data: `{"b":2}`, expect: true},
+		{name: "nor", query: `{"$nor":[{"a":1},{"b":2}]}`, data: `{"b":2}`, expect: false},
+	}
+
+	ctx := context.Background()
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var q map[string]interface{}
+				err := json.Unmarshal([]byte(st.query), &q)
+				assert.NoErrorf(t, err, "unmarshal error")
+				var data interface{}
+				err = json.Unmarshal([]byte(st.data), &data)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				c, err := FromMongo(q)
+				assert.NoError(t, err)
+				cf, err := Compile(c)
+				assert.NoError(t, err)
+				assert.Equal(t, st.expect, IsTrue(cf(ctx, data)))
+			})
+		})
+	}
+}
+
+func TestMongoRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		arg := randomMongoRule(r, 3)
+		c := arg.Clause
+		if c == nil {
+			c = &Clause{Arguments: Arguments{arg}}
+		}
+		rule, err := json.Marshal(c)
+		assert.NoError(t, err)
+
+		q, err := ToMongo(c)
+		if !assert.NoErrorf(t, err, "converting %s", rule) {
+			continue
+		}
+		rt, err := FromMongo(q)
+		if !assert.NoErrorf(t, err, "converting back %v", q) {
+			continue
+		}
+
+		// Converting the round tripped rule gives the same query.
+		rtq, err := ToMongo(rt)
+		assert.NoError(t, err)
+		assert.Equalf(t, q, rtq, "query for %s", rule)
+
+		// The round tripped rule gives the same result.
+		cf, err := Compile(c)
+		assert.NoError(t, err)
+		rtcf, err := Compile(rt)
+		assert.NoError(t, err)
+		for j := 0; j < 10; j++ {
+			data := randomMongoData(r)
+			assert.Equalf(t, IsTrue(cf(ctx, data)), IsTrue(rtcf(ctx, data)), "result of %s for %v", rule, data)
+		}
+	}
+}
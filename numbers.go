@@ -0,0 +1,189 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// isNumber reports whether i is one of the numeric types we support. As
+// well as the float64 produced by json.Unmarshal, we support json.Number
+// (as produced by a json.Decoder using UseNumber), and all of the Go
+// integer and float types.
+func isNumber(i interface{}) bool {
+	switch i.(type) {
+	case float64, float32, json.Number,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// isExactNumber reports whether i is a numeric type that may hold values
+// that can not be represented exactly as a float64, and so needs to be
+// compared exactly.
+func isExactNumber(i interface{}) bool {
+	switch i.(type) {
+	case json.Number,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// goNumberToFloat converts the Go numeric types, other than float64 and
+// json.Number, to a float64.
+func goNumberToFloat(i interface{}) (float64, bool) {
+	switch v := i.(type) {
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uintptr:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// goNumberToString formats the Go numeric types, other than float64 and
+// json.Number, without loss of precision.
+func goNumberToString(i interface{}) (string, bool) {
+	switch v := i.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case uintptr:
+		return strconv.FormatUint(uint64(v), 10), true
+	default:
+		return "", false
+	}
+}
+
+// isIntegerString reports whether s is an optionally signed string of
+// decimal digits.
+func isIntegerString(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// toBigFloat converts a value to an exact big.Float. Integers, including
+// integer strings and json.Numbers, are converted exactly. Other values are
+// converted as per toNumber. ok is false for NaN.
+func toBigFloat(i interface{}) (*big.Float, bool) {
+	var str string
+	switch v := i.(type) {
+	case int, int8, int16, int32, int64:
+		str, _ = goNumberToString(v)
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		str, _ = goNumberToString(v)
+	case json.Number:
+		str = v.String()
+	case string:
+		str = strings.TrimFunc(v, isJSSpace)
+	}
+
+	if isIntegerString(str) {
+		if n, ok := new(big.Int).SetString(str, 10); ok {
+			return new(big.Float).SetInt(n), true
+		}
+	}
+
+	f := toNumber(i)
+	if math.IsNaN(f) {
+		return nil, false
+	}
+	return new(big.Float).SetFloat64(f), true
+}
+
+func compareFloats(l, r float64) (int, bool) {
+	switch {
+	case math.IsNaN(l) || math.IsNaN(r):
+		return 0, false
+	case l < r:
+		return -1, true
+	case l > r:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// compareNumbers compares two values numerically, returning -1, 0 or 1.
+// ok is false if either value is NaN, in which case all comparisons are
+// false. Integer values, such as int64 IDs, are compared without loss of
+// precision.
+func compareNumbers(l, r interface{}) (int, bool) {
+	lf, lisfloat := l.(float64)
+	rf, risfloat := r.(float64)
+	switch {
+	case lisfloat && risfloat:
+		return compareFloats(lf, rf)
+	case !isExactNumber(l) && !isExactNumber(r):
+		return compareFloats(toNumber(l), toNumber(r))
+	}
+
+	lb, lok := toBigFloat(l)
+	rb, rok := toBigFloat(r)
+	if !lok || !rok {
+		return 0, false
+	}
+	return lb.Cmp(rb), true
+}
+
+// numbersEqual compares two values numerically.
+func numbersEqual(l, r interface{}) bool {
+	c, ok := compareNumbers(l, r)
+	return ok && c == 0
+}
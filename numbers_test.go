@@ -0,0 +1,212 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericTypes(t *testing.T) {
+	values := []interface{}{
+		float64(3), float32(3), json.Number("3"), json.Number("3.0"),
+		int(3), int8(3), int16(3), int32(3), int64(3),
+		uint(3), uint8(3), uint16(3), uint32(3), uint64(3), uintptr(3),
+	}
+
+	for _, v := range values {
+		t.Run(toString(v), func(t *testing.T) {
+			assert.True(t, isNumber(v))
+			assert.True(t, IsTrue(v))
+			assert.Equal(t, float64(3), toNumber(v))
+			for _, o := range values {
+				assert.Truef(t, IsEqual(v, o), "%T === %T", v, o)
+				assert.Truef(t, IsSoftEqual(v, o), "%T == %T", v, o)
+				assert.Truef(t, IsDeepEqual(v, o), "%T deep== %T", v, o)
+			}
+			assert.True(t, IsSoftEqual(v, "3"))
+			assert.False(t, IsEqual(v, "3"))
+		})
+	}
+
+	zeros := []interface{}{float32(0), json.Number("0"), json.Number("0.0"), int(0), int64(0), uint8(0)}
+	for _, v := range zeros {
+		assert.Falsef(t, IsTrue(v), "%T(0) should be falsy", v)
+	}
+
+	assert.Equal(t, "3", toString(int64(3)))
+	assert.Equal(t, "-3", toString(int8(-3)))
+	assert.Equal(t, "3.5", toString(float32(3.5)))
+	assert.Equal(t, "18446744073709551615", toString(uint64(18446744073709551615)))
+	assert.Equal(t, "12345678901234567890", toString(json.Number("12345678901234567890")))
+}
+
+func TestLargeIntegers(t *testing.T) {
+	// These are distinct integers, but are the same float64.
+	a := int64(9007199254740993)
+	b := int64(9007199254740992)
+	assert.Equal(t, float64(a), float64(b))
+
+	assert.False(t, IsEqual(a, b))
+	assert.False(t, IsSoftEqual(a, b))
+	assert.False(t, IsDeepEqual(a, b))
+	assert.True(t, IsEqual(a, json.Number("9007199254740993")))
+	assert.False(t, IsEqual(json.Number("9007199254740993"), json.Number("9007199254740992")))
+	assert.True(t, IsSoftEqual(a, "9007199254740993"))
+	assert.False(t, IsSoftEqual(a, "9007199254740992"))
+	assert.False(t, IsEqual(b, float64(9007199254740993)) && IsEqual(a, float64(9007199254740993)))
+
+	c, ok := compareNumbers(a, b)
+	assert.True(t, ok)
+	assert.Equal(t, 1, c)
+
+	c, ok = compareNumbers(uint64(18446744073709551615), int64(-1))
+	assert.True(t, ok)
+	assert.Equal(t, 1, c)
+
+	c, ok = compareNumbers(json.Number("100000000000000000000001"), json.Number("1e23"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, c)
+
+	_, ok = compareNumbers(int64(1), "abc")
+	assert.False(t, ok)
+}
+
+func TestNumericTypesOps(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   interface{}
+		expect interface{}
+	}
+
+	decode := func(s string) interface{} {
+		var data interface{}
+		dec := json.NewDecoder(bytes.NewBufferString(s))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			t.Fatalf("could not decode data, %v", err)
+		}
+		return data
+	}
+
+	ids := decode(`{"id": 9007199254740993, "other": 9007199254740992, "allowed": [9007199254740991, 9007199254740993]}`)
+	native := map[string]interface{}{
+		"age":   int(21),
+		"score": int64(9007199254740993),
+		"ratio": float32(0.5),
+		"count": uint8(0),
+		"items": []interface{}{int(1), int(2), int(3)},
+	}
+
+	tests := []test{
+		{
+			name:   "id-equal",
+			rule:   `{"==":[{"var":"id"},{"var":"other"}]}`,
+			data:   ids,
+			expect: false,
+		},
+		{
+			name:   "id-strict-equal",
+			rule:   `{"===":[{"var":"id"},{"var":"id"}]}`,
+			data:   ids,
+			expect: true,
+		},
+		{
+			name:   "id-greater",
+			rule:   `{">":[{"var":"id"},{"var":"other"}]}`,
+			data:   ids,
+			expect: true,
+		},
+		{
+			name:   "id-between",
+			rule:   `{"<":[{"var":"other"},{"var":"id"},9007199254740995]}`,
+			data:   ids,
+			expect: true,
+		},
+		{
+			name:   "id-in",
+			rule:   `{"in":[{"var":"other"},{"var":"allowed"}]}`,
+			data:   ids,
+			expect: false,
+		},
+		{
+			name:   "id-in-found",
+			rule:   `{"in":[{"var":"id"},{"var":"allowed"}]}`,
+			data:   ids,
+			expect: true,
+		},
+		{
+			name:   "native-compare",
+			rule:   `{">=":[{"var":"age"},21]}`,
+			data:   native,
+			expect: true,
+		},
+		{
+			name:   "native-plus",
+			rule:   `{"+":[{"var":"age"},{"var":"ratio"}]}`,
+			data:   native,
+			expect: 21.5,
+		},
+		{
+			name:   "native-falsy",
+			rule:   `{"if":[{"var":"count"},"yes","no"]}`,
+			data:   native,
+			expect: "no",
+		},
+		{
+			name:   "native-index",
+			rule:   `{"get":[{"var":"list"},{"var":"count"}]}`,
+			data:   map[string]interface{}{"list": []interface{}{"zero"}, "count": uint8(0)},
+			expect: "zero",
+		},
+		{
+			name:   "native-cat",
+			rule:   `{"cat":[{"var":"score"},"/",{"var":"ratio"}]}`,
+			data:   native,
+			expect: "9007199254740993/0.5",
+		},
+		{
+			name:   "native-filter",
+			rule:   `{"filter":[{"var":"items"},{">":[{"var":""},1]}]}`,
+			data:   native,
+			expect: []interface{}{int(2), int(3)},
+		},
+		{
+			name:   "json-number-missing-some",
+			rule:   `{"missing_some":[{"var":"need"},["a","b"]]}`,
+			data:   decode(`{"need": 1, "a": 1}`),
+			expect: []interface{}{},
+		},
+		{
+			name:   "json-number-substr",
+			rule:   `{"substr":["jsonlogic",{"var":"start"},{"var":"len"}]}`,
+			data:   decode(`{"start": 4, "len": 3}`),
+			expect: "log",
+		},
+		{
+			name:   "json-number-sort",
+			rule:   `{"sort":{"var":"allowed"}}`,
+			data:   decode(`{"allowed": [9007199254740993, 9007199254740992, 1]}`),
+			expect: []interface{}{json.Number("1"), json.Number("9007199254740992"), json.Number("9007199254740993")},
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
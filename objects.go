@@ -0,0 +1,245 @@
+package jsonlogic
+
+import (
+	"context"
+	"sort"
+)
+
+const (
+	// Object operations
+	objectOp       = "object"
+	getOp          = "get"
+	keysOp         = "keys"
+	valuesOp       = "values"
+	entriesOp      = "entries"
+	setOp          = "set"
+	mergeObjectsOp = "merge_objects"
+	pickOp         = "pick"
+	omitOp         = "omit"
+)
+
+func emptyMap(ctx context.Context, data interface{}) interface{} {
+	return map[string]interface{}{}
+}
+
+// sortedKeys returns the keys of a map in order, so that operations
+// producing arrays from maps are deterministic.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	resp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		resp[k] = v
+	}
+	return resp
+}
+
+// buildObjectOp builds the object operation, which takes alternating keys
+// and values, e.g. {"object": ["a", 1, "b", {"var": "b"}]}. Keys are
+// converted to strings, and a trailing key with no value is set to null.
+func buildObjectOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	termArgs, err := buildTermArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		resp := make(map[string]interface{}, (len(termArgs)+1)/2)
+		for i := 0; i < len(termArgs); i += 2 {
+			key := toString(termArgs[i](ctx, data))
+			var val interface{}
+			if i+1 < len(termArgs) {
+				val = termArgs[i+1](ctx, data)
+			}
+			resp[key] = val
+		}
+		return resp
+	}, nil
+}
+
+// buildGetOp builds the get operation, which looks up a (possibly dotted)
+// key in a computed object or array, with an optional default, e.g.
+// {"get": [{"object": ["a", 1]}, "a", 0]}.
+func buildGetOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	keyArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultArg := nullf
+	if len(args) >= 3 {
+		if defaultArg, err = BuildArgFunc(args[2], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		if v := DottedRef(lArg(ctx, data), keyArg(ctx, data)); v != nil {
+			return v
+		}
+		return defaultArg(ctx, data)
+	}, nil
+}
+
+// buildMapViewOp builds operations that take a single object and return an
+// array derived from it, in key order.
+func buildMapViewOp(view func(m map[string]interface{}, k string) interface{}) func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		if len(args) == 0 {
+			return emptySlice, nil
+		}
+
+		lArg, err := BuildArgFunc(args[0], ops)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, data interface{}) interface{} {
+			m, ok := lArg(ctx, data).(map[string]interface{})
+			if !ok {
+				return []interface{}{}
+			}
+
+			keys := sortedKeys(m)
+			resp := make([]interface{}, len(keys))
+			for i, k := range keys {
+				resp[i] = view(m, k)
+			}
+			return resp
+		}, nil
+	}
+}
+
+var (
+	buildKeysOp = buildMapViewOp(func(m map[string]interface{}, k string) interface{} {
+		return k
+	})
+	buildValuesOp = buildMapViewOp(func(m map[string]interface{}, k string) interface{} {
+		return m[k]
+	})
+	buildEntriesOp = buildMapViewOp(func(m map[string]interface{}, k string) interface{} {
+		return []interface{}{k, m[k]}
+	})
+)
+
+// buildSetOp builds the set operation, which returns a copy of an object
+// with a key set to a new value, e.g. {"set": [{"var": "cfg"}, "a", 1]}.
+// The original object is not modified.
+func buildSetOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptyMap, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) < 3 {
+		return func(ctx context.Context, data interface{}) interface{} {
+			m, _ := lArg(ctx, data).(map[string]interface{})
+			return copyMap(m)
+		}, nil
+	}
+
+	keyArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+	valArg, err := BuildArgFunc(args[2], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		m, _ := lArg(ctx, data).(map[string]interface{})
+		resp := copyMap(m)
+		resp[toString(keyArg(ctx, data))] = valArg(ctx, data)
+		return resp
+	}, nil
+}
+
+// buildMergeObjectsOp builds the merge_objects operation, a shallow merge
+// of its arguments where later keys win. Arguments that are not objects are
+// ignored.
+func buildMergeObjectsOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	termArgs, err := buildTermArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		resp := map[string]interface{}{}
+		for _, ta := range termArgs {
+			m, ok := ta(ctx, data).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for k, v := range m {
+				resp[k] = v
+			}
+		}
+		return resp
+	}, nil
+}
+
+// buildKeyFilterOp builds operations that take an object followed by keys,
+// given individually or as arrays, and return a copy of the object with
+// either only those keys (pick), or without them (omit).
+func buildKeyFilterOp(pick bool) func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		if len(args) == 0 {
+			return emptyMap, nil
+		}
+
+		lArg, err := BuildArgFunc(args[0], ops)
+		if err != nil {
+			return nil, err
+		}
+
+		termArgs, err := buildTermArgs(args[1:], ops)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, data interface{}) interface{} {
+			m, _ := lArg(ctx, data).(map[string]interface{})
+
+			keys := map[string]bool{}
+			for _, ta := range termArgs {
+				for _, k := range toSlice(ta(ctx, data)) {
+					keys[toString(k)] = true
+				}
+			}
+
+			resp := map[string]interface{}{}
+			for k, v := range m {
+				if keys[k] == pick {
+					resp[k] = v
+				}
+			}
+			return resp
+		}, nil
+	}
+}
+
+var (
+	buildPickOp = buildKeyFilterOp(true)
+	buildOmitOp = buildKeyFilterOp(false)
+)
@@ -0,0 +1,193 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectOps(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   interface{}
+		expect interface{}
+	}
+
+	data := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":    "ann",
+			"country": "GB",
+			"age":     float64(31),
+		},
+		"defaults": map[string]interface{}{
+			"theme": "light",
+			"beta":  false,
+		},
+	}
+
+	tests := []test{
+		{
+			name: "object",
+			rule: `{"object":["name",{"var":"user.name"},"adult",{">=":[{"var":"user.age"},18]}]}`,
+			data: data,
+			expect: map[string]interface{}{
+				"name":  "ann",
+				"adult": true,
+			},
+		},
+		{
+			name:   "object-empty",
+			rule:   `{"object":[]}`,
+			expect: map[string]interface{}{},
+		},
+		{
+			name:   "object-odd-args",
+			rule:   `{"object":["a",1,2]}`,
+			expect: map[string]interface{}{"a": float64(1), "2": nil},
+		},
+		{
+			name:   "get",
+			rule:   `{"get":[{"object":["a",{"object":["b",2]}]},"a.b"]}`,
+			expect: float64(2),
+		},
+		{
+			name:   "get-default",
+			rule:   `{"get":[{"var":"defaults"},"missing","dflt"]}`,
+			data:   data,
+			expect: "dflt",
+		},
+		{
+			name:   "get-false",
+			rule:   `{"get":[{"var":"defaults"},"beta",true]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "keys",
+			rule:   `{"keys":{"var":"user"}}`,
+			data:   data,
+			expect: []interface{}{"age", "country", "name"},
+		},
+		{
+			name:   "keys-non-object",
+			rule:   `{"keys":[[1,2]]}`,
+			expect: []interface{}{},
+		},
+		{
+			name:   "values",
+			rule:   `{"values":{"var":"user"}}`,
+			data:   data,
+			expect: []interface{}{float64(31), "GB", "ann"},
+		},
+		{
+			name: "entries",
+			rule: `{"entries":{"var":"defaults"}}`,
+			data: data,
+			expect: []interface{}{
+				[]interface{}{"beta", false},
+				[]interface{}{"theme", "light"},
+			},
+		},
+		{
+			name: "set",
+			rule: `{"set":[{"var":"defaults"},"beta",true]}`,
+			data: data,
+			expect: map[string]interface{}{
+				"theme": "light",
+				"beta":  true,
+			},
+		},
+		{
+			name:   "set-non-object",
+			rule:   `{"set":[null,"a",1]}`,
+			expect: map[string]interface{}{"a": float64(1)},
+		},
+		{
+			name: "merge-objects",
+			rule: `{"merge_objects":[{"var":"defaults"},{"object":["theme","dark"]},1]}`,
+			data: data,
+			expect: map[string]interface{}{
+				"theme": "dark",
+				"beta":  false,
+			},
+		},
+		{
+			name: "pick",
+			rule: `{"pick":[{"var":"user"},"name",["age","missing"]]}`,
+			data: data,
+			expect: map[string]interface{}{
+				"name": "ann",
+				"age":  float64(31),
+			},
+		},
+		{
+			name: "omit",
+			rule: `{"omit":[{"var":"user"},"name"]}`,
+			data: data,
+			expect: map[string]interface{}{
+				"country": "GB",
+				"age":     float64(31),
+			},
+		},
+		{
+			name:   "truthy-empty-object",
+			rule:   `{"!!":[{"object":[]}]}`,
+			expect: true,
+		},
+		{
+			name:   "if-object",
+			rule:   `{"if":[{"pick":[{"var":"user"},"none"]},"yes","no"]}`,
+			data:   data,
+			expect: "yes",
+		},
+		{
+			name:   "in-object-keys",
+			rule:   `{"in":["theme",{"set":[{"var":"defaults"},"x",1]}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "in-deep-equal-objects",
+			rule:   `{"in":[{"object":["a",1]},[{"object":["a","1"]}]]}`,
+			expect: true,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
+
+func TestObjectOpsDoNotModifyData(t *testing.T) {
+	data := map[string]interface{}{
+		"cfg": map[string]interface{}{"a": float64(1)},
+	}
+
+	var c Clause
+	err := json.Unmarshal([]byte(`{"set":[{"var":"cfg"},"a",2]}`), &c)
+	assert.NoError(t, err)
+
+	cf, err := Compile(&c)
+	assert.NoError(t, err)
+
+	res := cf(context.Background(), data)
+	assert.Equal(t, map[string]interface{}{"a": float64(2)}, res)
+	assert.Equal(t, map[string]interface{}{"a": float64(1)}, data["cfg"])
+	assert.False(t, IsDeepEqual(res, data["cfg"]))
+	assert.True(t, IsDeepEqual(res, map[string]interface{}{"a": "2"}))
+}
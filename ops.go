@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
-	"unicode/utf8"
+	"unicode/utf16"
 )
 
 const (
@@ -102,7 +102,38 @@ func BuildArgFunc(arg Argument, ops OpsSet) (ClauseFunc, error) {
 	return ops.Compile(arg.Clause)
 }
 
+// constantArg reports whether an argument is a literal value that does not
+// depend on the data, and returns that value.
+func constantArg(arg Argument) (interface{}, bool) {
+	switch {
+	case arg.Clause == nil:
+		return arg.Value, true
+	case arg.Clause.Operator.Name == nullOp &&
+		len(arg.Clause.Arguments) == 1 &&
+		arg.Clause.Arguments[0].Clause == nil:
+		return arg.Clause.Arguments[0].Value, true
+	default:
+		return nil, false
+	}
+}
+
+// buildTermArgs calls BuildArgFunc for each of the arguments.
+func buildTermArgs(args Arguments, ops OpsSet) ([]ClauseFunc, error) {
+	termArgs := make([]ClauseFunc, len(args))
+	for i, a := range args {
+		termArg, err := BuildArgFunc(a, ops)
+		if err != nil {
+			return nil, err
+		}
+		termArgs[i] = termArg
+	}
+	return termArgs, nil
+}
+
 func buildNullOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
 	if args[0].Clause == nil {
 		return func(ctx context.Context, data interface{}) interface{} {
 			return args[0].Value
@@ -150,31 +181,34 @@ func buildVarOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		indexVal := indexArg(ctx, data)
-		defaultVal := defaultArg(ctx, data)
-
-		// if the index is an empty string, we don't care about
-		// the type and return the entire thing.
-		indexstr, ok := indexVal.(string)
-		if ok && indexstr == "" {
-			return data
-		}
+		return lookupVar(data, indexArg(ctx, data), defaultArg(ctx, data))
+	}, nil
+}
 
-		// otherwise, we assume this is an indexable type.
-		switch data := data.(type) {
-		case map[string]interface{}:
-			v := DottedRef(data, indexVal)
-			if v != nil {
-				return v
-			}
-		case []interface{}:
-			v := DottedRef(data, indexVal)
-			if v != nil {
-				return v
-			}
+// lookupVar resolves a var reference against data, returning defaultVal
+// if it cannot be found.
+func lookupVar(data, indexVal, defaultVal interface{}) interface{} {
+	// if the index is an empty string, we don't care about
+	// the type and return the entire thing.
+	indexstr, ok := indexVal.(string)
+	if ok && indexstr == "" {
+		return data
+	}
+
+	// otherwise, we assume this is an indexable type.
+	switch data := data.(type) {
+	case map[string]interface{}:
+		v := DottedRef(data, indexVal)
+		if v != nil {
+			return v
 		}
-		return defaultVal
-	}, nil
+	case []interface{}:
+		v := DottedRef(data, indexVal)
+		if v != nil {
+			return v
+		}
+	}
+	return defaultVal
 }
 
 func buildMissingOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
@@ -237,10 +271,10 @@ func buildMissingSomeOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 
 	return func(ctx context.Context, data interface{}) interface{} {
 		required := requiredArg(ctx, data)
-		requiredfloat, ok := required.(float64)
-		if !ok {
+		if !isNumber(required) {
 			return []interface{}{}
 		}
+		requiredfloat := toNumber(required)
 
 		terms := termsArg(ctx, data)
 		termsslice, ok := terms.([]interface{})
@@ -346,6 +380,13 @@ func buildIfOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 func buildTernaryOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	var err error
 
+	switch {
+	case len(args) == 0:
+		return nullf, nil
+	case len(args) == 1:
+		return BuildArgFunc(args[0], ops)
+	}
+
 	termArg, err := BuildArgFunc(args[0], ops)
 	if err != nil {
 		return nil, err
@@ -487,10 +528,8 @@ func buildGreaterOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := toNumber(lArg(ctx, data))
-		rVal := toNumber(rArg(ctx, data))
-
-		return lVal > rVal
+		c, ok := compareJS(lArg(ctx, data), rArg(ctx, data))
+		return ok && c > 0
 	}, nil
 }
 
@@ -516,10 +555,8 @@ func buildGreaterEqualOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := toNumber(lArg(ctx, data))
-		rVal := toNumber(rArg(ctx, data))
-
-		return lVal >= rVal
+		c, ok := compareJS(lArg(ctx, data), rArg(ctx, data))
+		return ok && c >= 0
 	}, nil
 }
 
@@ -538,11 +575,13 @@ func buildBetweenExOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := toNumber(lArg(ctx, data))
-		mVal := toNumber(mArg(ctx, data))
-		rVal := toNumber(rArg(ctx, data))
+		lVal := lArg(ctx, data)
+		mVal := mArg(ctx, data)
+		rVal := rArg(ctx, data)
 
-		return lVal < mVal && mVal < rVal
+		lc, lok := compareJS(lVal, mVal)
+		rc, rok := compareJS(mVal, rVal)
+		return lok && rok && lc < 0 && rc < 0
 	}, nil
 }
 
@@ -561,11 +600,13 @@ func buildBetweenIncOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := toNumber(lArg(ctx, data))
-		mVal := toNumber(mArg(ctx, data))
-		rVal := toNumber(rArg(ctx, data))
+		lVal := lArg(ctx, data)
+		mVal := mArg(ctx, data)
+		rVal := rArg(ctx, data)
 
-		return lVal <= mVal && mVal <= rVal
+		lc, lok := compareJS(lVal, mVal)
+		rc, rok := compareJS(mVal, rVal)
+		return lok && rok && lc <= 0 && rc <= 0
 	}, nil
 }
 
@@ -589,10 +630,8 @@ func buildLessOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := toNumber(lArg(ctx, data))
-		rVal := toNumber(rArg(ctx, data))
-
-		return lVal < rVal
+		c, ok := compareJS(lArg(ctx, data), rArg(ctx, data))
+		return ok && c < 0
 	}, nil
 }
 
@@ -616,10 +655,8 @@ func buildLessEqualOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := toNumber(lArg(ctx, data))
-		rVal := toNumber(rArg(ctx, data))
-
-		return lVal <= rVal
+		c, ok := compareJS(lArg(ctx, data), rArg(ctx, data))
+		return ok && c <= 0
 	}, nil
 }
 
@@ -947,8 +984,8 @@ func buildInOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 
 		switch rval := rval.(type) {
 		case string:
-			lstr := fmt.Sprintf("%v", lval)
-			if strings.Contains(rval, lstr) {
+			// as per json-logic-js, nothing is in an empty string
+			if rval != "" && strings.Contains(rval, toString(lval)) {
 				return true
 			}
 			return false
@@ -986,12 +1023,47 @@ func buildCatOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	return func(ctx context.Context, data interface{}) interface{} {
 		resp := ""
 		for _, ta := range termArgs {
-			resp += fmt.Sprintf("%v", ta(ctx, data))
+			resp += toString(ta(ctx, data))
 		}
 		return resp
 	}, nil
 }
 
+// jsInteger converts a value to an integer as per JavaScript's
+// ToIntegerOrInfinity, truncating towards zero, with NaN as 0.
+func jsInteger(i interface{}) float64 {
+	f := toNumber(i)
+	if math.IsNaN(f) {
+		return 0
+	}
+	return math.Trunc(f)
+}
+
+// jsSubstr implements String.prototype.substr on a string of UTF-16 code
+// units. If hasLength is false, the length is undefined, and the rest of
+// the string is returned.
+func jsSubstr(units []uint16, start, length interface{}, hasLength bool) []uint16 {
+	size := float64(len(units))
+	s := jsInteger(start)
+	if s < 0 {
+		s = math.Max(size+s, 0)
+	}
+	s = math.Min(s, size)
+
+	e := size
+	if hasLength {
+		e = math.Min(s+jsInteger(length), size)
+	}
+	if s >= e {
+		return nil
+	}
+	return units[int(s):int(e)]
+}
+
+// buildSubstrOp builds the substr operation, which follows json-logic-js,
+// and so String.prototype.substr. Offsets and lengths count UTF-16 code
+// units, and a negative length is the number of characters to drop from
+// the end of the string.
 func buildSubstrOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	var err error
 	if len(args) == 0 {
@@ -1013,7 +1085,7 @@ func buildSubstrOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 		}
 	}
 
-	lengthArg := nullf
+	var lengthArg ClauseFunc
 	if len(args) >= 3 {
 		lengthArg, err = BuildArgFunc(args[2], ops)
 		if err != nil {
@@ -1022,76 +1094,28 @@ func buildSubstrOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 	}
 
 	return func(ctx context.Context, data interface{}) interface{} {
-		lVal := lArg(ctx, data)
+		base := toString(lArg(ctx, data))
+		units := utf16.Encode([]rune(base))
 		offsetVal := offsetArg(ctx, data)
-		lengthVal := lengthArg(ctx, data)
-
-		var base string
-		var ok bool
-		if base, ok = lVal.(string); !ok {
-			base = fmt.Sprintf("%v", lVal)
-		}
 
-		baseLen := utf8.RuneCountInString(base)
-		if baseLen == 0 {
-			return base
+		if lengthArg == nil {
+			return string(utf16.Decode(jsSubstr(units, offsetVal, nil, false)))
 		}
 
-		offset := 0.0
-		offset, _ = offsetVal.(float64)
-		offsetint := int(offset)
-
-		length := 0.0
-		length, _ = lengthVal.(float64)
-		lengthint := int(length)
-
-		start := 0
-		end := baseLen
-
-		switch {
-		case offsetint > 0:
-			if offsetint > len(base) {
-				offsetint = len(base)
-			}
-			start = offsetint
-		case offsetint < 0:
-			if offsetint < (-1 * len(base)) {
-				offsetint = -1 * len(base)
-			}
-
-			start = len(base) + offsetint
-		}
-
-		switch {
-		case lengthint > 0:
-			if start+lengthint > baseLen {
-				lengthint = baseLen - start
-			}
-			end = start + lengthint
-		case lengthint < 0:
-			remaining := baseLen - start
-			if lengthint*-1 > remaining {
-				lengthint = remaining * -1
-			}
-			end += lengthint
-		}
-
-		resp := ""
-		i := 0
-		for _, c := range base {
-			if i < start {
-				i++
-				continue
-			}
-			if i >= end {
-				break
+		lengthVal := lengthArg(ctx, data)
+		if c, ok := compareJS(lengthVal, 0.0); ok && c < 0 {
+			temp := jsSubstr(units, offsetVal, nil, false)
+
+			// temp.length + length, which JavaScript treats as string
+			// concatenation if length is not a number, e.g. "-1".
+			var end interface{} = float64(len(temp)) + toNumber(lengthVal)
+			if _, isstr := toPrimitive(lengthVal).(string); isstr {
+				end = toString(float64(len(temp))) + toString(lengthVal)
 			}
-
-			resp += string(c)
-			i++
+			return string(utf16.Decode(jsSubstr(temp, 0.0, end, true)))
 		}
 
-		return resp
+		return string(utf16.Decode(jsSubstr(units, offsetVal, lengthVal, true)))
 	}, nil
 }
 
@@ -1119,8 +1143,10 @@ func buildMapOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 
 		resp := make([]interface{}, len(lslice))
 
+		sctx, frame := pushScope(ctx, data)
 		for i, subd := range lslice {
-			resp[i] = rArg(ctx, subd)
+			frame.index = i
+			resp[i] = rArg(sctx, subd)
 		}
 
 		return resp
@@ -1152,8 +1178,10 @@ func buildFilterOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 		resp := make([]interface{}, len(lslice))
 
 		n := 0
-		for _, subd := range lslice {
-			if IsTrue(rArg(ctx, subd)) {
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			if IsTrue(rArg(sctx, subd)) {
 				resp[n] = subd
 				n++
 			}
@@ -1193,8 +1221,10 @@ func buildReduceOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 			return acc
 		}
 
-		for _, subd := range lslice {
-			acc = fArg(ctx, map[string]interface{}{
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			acc = fArg(sctx, map[string]interface{}{
 				"current":     subd,
 				"accumulator": acc,
 			})
@@ -1229,8 +1259,10 @@ func buildAllOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 			return false
 		}
 
-		for _, subd := range lslice {
-			if !IsTrue(fArg(ctx, subd)) {
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			if !IsTrue(fArg(sctx, subd)) {
 				return false
 			}
 		}
@@ -1264,8 +1296,10 @@ func buildSomeOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 			return false
 		}
 
-		for _, subd := range lslice {
-			if IsTrue(fArg(ctx, subd)) {
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			if IsTrue(fArg(sctx, subd)) {
 				return true
 			}
 		}
@@ -1299,8 +1333,10 @@ func buildNoneOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
 			return true
 		}
 
-		for _, subd := range lslice {
-			if IsTrue(fArg(ctx, subd)) {
+		sctx, frame := pushScope(ctx, data)
+		for i, subd := range lslice {
+			frame.index = i
+			if IsTrue(fArg(sctx, subd)) {
 				return false
 			}
 		}
@@ -1320,7 +1356,7 @@ func (ops OpsSet) Compile(c *Clause) (ClauseFunc, error) {
 }
 
 // DefaultOps is the default set of operations as specified on the jsonlogic
-// site.
+// site, along with the extension operations provided by this package.
 var DefaultOps = OpsSet{
 	nullOp:          buildNullOp,
 	varOp:           buildVarOp,
@@ -1359,6 +1395,48 @@ var DefaultOps = OpsSet{
 	noneOp:   buildNoneOp,
 
 	mergeOp: buildMergeOp,
+
+	semverCompareOp:   buildSemverCompareOp,
+	semverSatisfiesOp: buildSemverSatisfiesOp,
+
+	ipInCIDROp:  buildIPInCIDROp,
+	ipVersionOp: buildIPVersionOp,
+
+	bucketOp: buildBucketOp,
+
+	unionOp:        buildUnionOp,
+	intersectionOp: buildIntersectionOp,
+	differenceOp:   buildDifferenceOp,
+	uniqueOp:       buildUniqueOp,
+	containsAllOp:  buildContainsAllOp,
+	containsAnyOp:  buildContainsAnyOp,
+
+	sortOp:    buildSortOp,
+	sliceOp:   buildSliceOp,
+	firstOp:   buildFirstOp,
+	lastOp:    buildLastOp,
+	countOp:   buildCountOp,
+	sumOp:     buildSumOp,
+	avgOp:     buildAvgOp,
+	groupByOp: buildGroupByOp,
+	findOp:    buildFindOp,
+
+	objectOp:       buildObjectOp,
+	getOp:          buildGetOp,
+	keysOp:         buildKeysOp,
+	valuesOp:       buildValuesOp,
+	entriesOp:      buildEntriesOp,
+	setOp:          buildSetOp,
+	mergeObjectsOp: buildMergeObjectsOp,
+	pickOp:         buildPickOp,
+	omitOp:         buildOmitOp,
+
+	letOp: buildLetOp,
+	valOp: buildValOp,
+
+	outerOp: buildOuterOp,
+	indexOp: buildIndexOp,
+	rootOp:  buildRootOp,
 }
 
 // Compile builds a ClauseFunc that will execute
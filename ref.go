@@ -40,14 +40,16 @@ func DottedRef(data interface{}, ref interface{}) interface{} {
 	switch ref := ref.(type) {
 	case string:
 		refStr = ref
-	case float64:
-		intref := int(ref)
-		if ref != float64(intref) || intref < 0 {
+	default:
+		if !isNumber(ref) {
+			return nil
+		}
+		f := toNumber(ref)
+		intref := int(f)
+		if f != float64(intref) || intref < 0 {
 			return nil
 		}
 		refStr = strconv.Itoa(intref)
-	default:
-		return nil
 	}
 
 	return deref(data, strings.Split(refStr, "."))
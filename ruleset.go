@@ -0,0 +1,173 @@
+package jsonlogic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Rule is a named rule in a RuleSet.
+type Rule struct {
+	Name string `json:"name"`
+	// Priority orders the rules, highest first. Rules with the same
+	// priority are kept in the order given.
+	Priority int      `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Enabled may be set to false to leave the rule out of the set. A nil
+	// Enabled means the rule is enabled.
+	Enabled *bool   `json:"enabled,omitempty"`
+	Rule    *Clause `json:"rule"`
+	// Payload is returned, unaltered, when the rule matches.
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+func (r Rule) enabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// RuleSetMode selects how a RuleSet picks the rules that match.
+type RuleSetMode int
+
+const (
+	// FirstMatch returns the first rule, in priority order, whose result
+	// is truthy.
+	FirstMatch RuleSetMode = iota
+	// AllMatches returns every rule, in priority order, whose result is
+	// truthy.
+	AllMatches
+	// Scored converts the result of every rule to a number, as per the
+	// JavaScript Number function, and returns every rule with a positive
+	// score, highest score first, then in priority order.
+	Scored
+)
+
+// EvaluateOptions configures RuleSet.Evaluate.
+type EvaluateOptions struct {
+	Mode RuleSetMode
+	// Tags, if not empty, restricts evaluation to rules with any of the
+	// tags.
+	Tags []string
+}
+
+// Match is a rule that matched in RuleSet.Evaluate.
+type Match struct {
+	Name     string
+	Priority int
+	Tags     []string
+	Payload  interface{}
+	// Result is the value the rule evaluated to.
+	Result interface{}
+	// Score is the result as a number, in the Scored mode.
+	Score float64
+}
+
+type ruleSetEntry struct {
+	Rule
+	cf ClauseFunc
+}
+
+// RuleSet holds a set of named rules, compiled once, to be evaluated
+// together. A RuleSet is safe for concurrent use.
+type RuleSet struct {
+	rules []ruleSetEntry
+}
+
+// NewRuleSet compiles the enabled rules with ops, or DefaultOps if ops is
+// nil. Rule names must be unique.
+func NewRuleSet(rules []Rule, ops OpsSet) (*RuleSet, error) {
+	if ops == nil {
+		ops = DefaultOps
+	}
+
+	rs := &RuleSet{}
+	names := map[string]bool{}
+	for _, r := range rules {
+		switch {
+		case r.Name == "":
+			return nil, fmt.Errorf("rule with no name")
+		case names[r.Name]:
+			return nil, fmt.Errorf("duplicate rule %q", r.Name)
+		case r.Rule == nil:
+			return nil, fmt.Errorf("rule %q has no rule", r.Name)
+		}
+		names[r.Name] = true
+		if !r.enabled() {
+			continue
+		}
+
+		cf, err := ops.Compile(r.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("could not compile rule %q, %w", r.Name, err)
+		}
+		rs.rules = append(rs.rules, ruleSetEntry{Rule: r, cf: cf})
+	}
+
+	sort.SliceStable(rs.rules, func(i, j int) bool {
+		return rs.rules[i].Priority > rs.rules[j].Priority
+	})
+	return rs, nil
+}
+
+// Rules returns the names of the enabled rules, in priority order.
+func (rs *RuleSet) Rules() []string {
+	names := make([]string, len(rs.rules))
+	for i, r := range rs.rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func (r ruleSetEntry) hasAnyTag(tags []string) bool {
+	for _, t := range tags {
+		for _, rt := range r.Tags {
+			if t == rt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Evaluate evaluates the rules against data, returning the rules that
+// matched, as selected by opts.Mode.
+func (rs *RuleSet) Evaluate(ctx context.Context, data interface{}, opts EvaluateOptions) []Match {
+	var matches []Match
+	for _, r := range rs.rules {
+		if len(opts.Tags) != 0 && !r.hasAnyTag(opts.Tags) {
+			continue
+		}
+
+		res := r.cf(ctx, data)
+		m := Match{
+			Name:     r.Name,
+			Priority: r.Priority,
+			Tags:     r.Tags,
+			Payload:  r.Payload,
+			Result:   res,
+		}
+		switch opts.Mode {
+		case Scored:
+			m.Score = toNumber(res)
+			if math.IsNaN(m.Score) || m.Score <= 0 {
+				continue
+			}
+		default:
+			if !IsTrue(res) {
+				continue
+			}
+		}
+
+		matches = append(matches, m)
+		if opts.Mode == FirstMatch {
+			break
+		}
+	}
+
+	if opts.Mode == Scored {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Score > matches[j].Score
+		})
+	}
+	return matches
+}
@@ -0,0 +1,177 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRuleSet = `[
+	{"name": "adult", "rule": {">=": [{"var": "age"}, 18]}, "tags": ["age"], "payload": {"segment": "adult"}},
+	{"name": "vip", "priority": 10, "rule": {"var": "vip"}, "tags": ["status"], "payload": "gold"},
+	{"name": "uk", "priority": 5, "rule": {"==": [{"var": "country"}, "GB"]}, "tags": ["geo"]},
+	{"name": "retired", "rule": {"nope": []}, "enabled": false},
+	{"name": "spend", "rule": {"/": [{"var": "spend"}, 100]}, "tags": ["status"]},
+	{"name": "visits", "priority": 1, "rule": {"var": "visits"}, "tags": ["status", "age"]}
+]`
+
+func TestRuleSetEvaluate(t *testing.T) {
+	type test struct {
+		name   string
+		data   string
+		opts   EvaluateOptions
+		expect []string
+		scores []float64
+	}
+
+	tests := []test{
+		{
+			name:   "first-match-priority",
+			data:   `{"age": 30, "vip": true, "country": "GB"}`,
+			opts:   EvaluateOptions{Mode: FirstMatch},
+			expect: []string{"vip"},
+		},
+		{
+			name:   "first-match-skips-false",
+			data:   `{"age": 30, "country": "FR"}`,
+			opts:   EvaluateOptions{Mode: FirstMatch},
+			expect: []string{"adult"},
+		},
+		{
+			name: "first-match-none",
+			data: `{"age": 3}`,
+			opts: EvaluateOptions{Mode: FirstMatch},
+		},
+		{
+			name:   "all-matches",
+			data:   `{"age": 30, "vip": true, "country": "GB", "visits": 2}`,
+			opts:   EvaluateOptions{Mode: AllMatches},
+			expect: []string{"vip", "uk", "visits", "adult"},
+		},
+		{
+			name:   "all-matches-tags",
+			data:   `{"age": 30, "vip": true, "country": "GB", "visits": 2}`,
+			opts:   EvaluateOptions{Mode: AllMatches, Tags: []string{"age", "geo"}},
+			expect: []string{"uk", "visits", "adult"},
+		},
+		{
+			name:   "scored",
+			data:   `{"age": 30, "vip": true, "spend": 250, "visits": 2, "country": "GB"}`,
+			opts:   EvaluateOptions{Mode: Scored},
+			expect: []string{"spend", "visits", "vip", "uk", "adult"},
+			scores: []float64{2.5, 2, 1, 1, 1},
+		},
+		{
+			name:   "scored-skips-non-positive",
+			data:   `{"age": 30, "spend": -100, "visits": "many"}`,
+			opts:   EvaluateOptions{Mode: Scored, Tags: []string{"status", "age"}},
+			expect: []string{"adult"},
+			scores: []float64{1},
+		},
+	}
+
+	var rules []Rule
+	err := json.Unmarshal([]byte(testRuleSet), &rules)
+	assert.NoError(t, err)
+	rs, err := NewRuleSet(rules, nil)
+	assert.NoError(t, err)
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var data interface{}
+				err := json.Unmarshal([]byte(st.data), &data)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				matches := rs.Evaluate(context.Background(), data, st.opts)
+				var names []string
+				var scores []float64
+				for _, m := range matches {
+					names = append(names, m.Name)
+					if st.opts.Mode == Scored {
+						scores = append(scores, m.Score)
+					}
+				}
+				assert.Equal(t, st.expect, names)
+				assert.Equal(t, st.scores, scores)
+			})
+		})
+	}
+}
+
+func TestRuleSetMatch(t *testing.T) {
+	var rules []Rule
+	err := json.Unmarshal([]byte(testRuleSet), &rules)
+	assert.NoError(t, err)
+	rs, err := NewRuleSet(rules, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"vip", "uk", "visits", "adult", "spend"}, rs.Rules())
+
+	matches := rs.Evaluate(context.Background(), map[string]interface{}{"age": 40.0}, EvaluateOptions{})
+	assert.Equal(t, []Match{{
+		Name:    "adult",
+		Tags:    []string{"age"},
+		Payload: map[string]interface{}{"segment": "adult"},
+		Result:  true,
+	}}, matches)
+}
+
+func TestNewRuleSet(t *testing.T) {
+	type test struct {
+		name  string
+		rules string
+		ops   OpsSet
+		err   string
+	}
+
+	tests := []test{
+		{
+			name:  "no-name",
+			rules: `[{"rule": true}]`,
+			err:   `rule with no name`,
+		},
+		{
+			name:  "duplicate",
+			rules: `[{"name": "a", "rule": true}, {"name": "a", "rule": false, "enabled": false}]`,
+			err:   `duplicate rule "a"`,
+		},
+		{
+			name:  "no-rule",
+			rules: `[{"name": "a"}]`,
+			err:   `rule "a" has no rule`,
+		},
+		{
+			name:  "compile-error",
+			rules: `[{"name": "a", "rule": {"nope": []}}]`,
+			err:   `could not compile rule "a", unrecognized operation nope`,
+		},
+		{
+			name:  "custom-ops",
+			rules: `[{"name": "a", "rule": {"nope": []}}]`,
+			ops: OpsSet{"nope": func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+				return truef, nil
+			}},
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var rules []Rule
+				err := json.Unmarshal([]byte(st.rules), &rules)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				rs, err := NewRuleSet(rules, st.ops)
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Len(t, rs.Evaluate(context.Background(), nil, EvaluateOptions{Mode: AllMatches}), len(rules))
+			})
+		})
+	}
+}
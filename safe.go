@@ -0,0 +1,111 @@
+package jsonlogic
+
+import (
+	"context"
+	"fmt"
+)
+
+// SafeClauseFunc is a compiled clause that returns an error, rather than
+// panicking, if any of its operations panic.
+type SafeClauseFunc func(ctx context.Context, data interface{}) (interface{}, error)
+
+// OpError is returned by SafeCompile and SafeClauseFunc when an operation
+// panics.
+type OpError struct {
+	// Op is the name of the innermost operation that panicked.
+	Op string
+	// Compile is true if the panic happened while the operation was being
+	// compiled, rather than evaluated.
+	Compile bool
+	// Value is the value that was passed to panic.
+	Value interface{}
+}
+
+func (e *OpError) Error() string {
+	phase := "evaluating"
+	if e.Compile {
+		phase = "compiling"
+	}
+	return fmt.Sprintf("panic %s operation %q: %v", phase, e.Op, e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it was an error, such as a
+// runtime.Error.
+func (e *OpError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverOpError converts a recovered panic value into an *OpError for op,
+// unless it already is one from a nested operation.
+func recoverOpError(op string, compile bool, r interface{}) *OpError {
+	if oerr, ok := r.(*OpError); ok {
+		return oerr
+	}
+	return &OpError{Op: op, Compile: compile, Value: r}
+}
+
+// safeBuilder wraps an operation builder so that panics during compilation
+// are returned as errors, and panics during evaluation are re-raised as an
+// *OpError naming the operation.
+func safeBuilder(op string, bf func(args Arguments, ops OpsSet) (ClauseFunc, error)) func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	return func(args Arguments, ops OpsSet) (cf ClauseFunc, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				cf, err = nil, recoverOpError(op, true, r)
+			}
+		}()
+
+		f, err := bf(args, ops)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, data interface{}) interface{} {
+			defer func() {
+				if r := recover(); r != nil {
+					panic(recoverOpError(op, false, r))
+				}
+			}()
+			return f(ctx, data)
+		}, nil
+	}
+}
+
+// SafeCompile compiles a clause, as per Compile, but recovers any panics
+// from the operations in the set, including custom operations, during both
+// compilation and evaluation. Panics are returned as an *OpError naming the
+// operation, so that one bad rule can not crash the caller. Recovering
+// panics has a small cost for every operation evaluated.
+func (ops OpsSet) SafeCompile(c *Clause) (sf SafeClauseFunc, err error) {
+	safe := make(OpsSet, len(ops))
+	for k, v := range ops {
+		safe[k] = safeBuilder(k, v)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			sf, err = nil, recoverOpError("", true, r)
+		}
+	}()
+
+	cf, err := safe.Compile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, recoverOpError(c.Operator.Name, false, r)
+			}
+		}()
+		return cf(ctx, data), nil
+	}, nil
+}
+
+// SafeCompile compiles a clause using DefaultOps, recovering panics as per
+// OpsSet.SafeCompile.
+func SafeCompile(c *Clause) (SafeClauseFunc, error) {
+	return DefaultOps.SafeCompile(c)
+}
@@ -0,0 +1,128 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeCompile(t *testing.T) {
+	ops := OpsSet{}
+	for k, v := range DefaultOps {
+		ops[k] = v
+	}
+	ops["bad_compile"] = func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		panic("bad compile")
+	}
+	ops["bad_eval"] = func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+		return func(ctx context.Context, data interface{}) interface{} {
+			var s []interface{}
+			return s[len(args)]
+		}, nil
+	}
+
+	type test struct {
+		name       string
+		rule       string
+		data       interface{}
+		expect     interface{}
+		compileErr string
+		evalErr    string
+	}
+
+	tests := []test{
+		{
+			name:   "no-panic",
+			rule:   `{"and":[{"var":"a"},{"+":[1,2]}]}`,
+			data:   map[string]interface{}{"a": true},
+			expect: float64(3),
+		},
+		{
+			name:       "compile-panic",
+			rule:       `{"bad_compile":[]}`,
+			compileErr: `panic compiling operation "bad_compile": bad compile`,
+		},
+		{
+			name:       "nested-compile-panic",
+			rule:       `{"if":[true,{"map":[[1],{"bad_compile":[]}]}]}`,
+			compileErr: `panic compiling operation "bad_compile": bad compile`,
+		},
+		{
+			name:    "eval-panic",
+			rule:    `{"bad_eval":[]}`,
+			evalErr: `panic evaluating operation "bad_eval": runtime error: index out of range [0] with length 0`,
+		},
+		{
+			name:    "nested-eval-panic",
+			rule:    `{"map":[[1,2],{"+":[{"var":""},{"bad_eval":[1]}]}]}`,
+			evalErr: `panic evaluating operation "bad_eval": runtime error: index out of range [1] with length 0`,
+		},
+		{
+			name:   "short-circuit-avoids-panic",
+			rule:   `{"or":[true,{"bad_eval":[]}]}`,
+			expect: true,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				sf, err := ops.SafeCompile(&c)
+				if st.compileErr != "" {
+					assert.EqualErrorf(t, err, st.compileErr, "compile error")
+					return
+				}
+				assert.NoErrorf(t, err, "compile error")
+
+				v, err := sf(context.Background(), st.data)
+				if st.evalErr != "" {
+					assert.EqualErrorf(t, err, st.evalErr, "eval error")
+					assert.Nil(t, v)
+					return
+				}
+				assert.NoErrorf(t, err, "eval error")
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
+
+func TestSafeCompileOpError(t *testing.T) {
+	ops := OpsSet{
+		"bad": func(args Arguments, ops OpsSet) (ClauseFunc, error) {
+			return func(ctx context.Context, data interface{}) interface{} {
+				var m map[string]interface{}
+				m["a"] = 1
+				return nil
+			}, nil
+		},
+	}
+
+	sf, err := ops.SafeCompile(&Clause{Operator: Operator{Name: "bad"}})
+	assert.NoError(t, err)
+
+	_, err = sf(context.Background(), nil)
+	var oerr *OpError
+	if assert.True(t, errors.As(err, &oerr)) {
+		assert.Equal(t, "bad", oerr.Op)
+		assert.False(t, oerr.Compile)
+	}
+
+	var rerr runtime.Error
+	assert.True(t, errors.As(err, &rerr), "unwraps to the runtime error")
+}
+
+func TestSafeCompileNilClause(t *testing.T) {
+	assert.NotPanics(t, func() {
+		_, err := SafeCompile(nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,142 @@
+package jsonlogic
+
+import (
+	"context"
+)
+
+const (
+	// Scope operations
+	outerOp = "outer"
+	indexOp = "index"
+	rootOp  = "root"
+)
+
+type scopeFrameKey struct{}
+
+// scopeFrame records the data an array operation was called with, while
+// its inner expression is evaluated against each element. Frames are
+// carried in the context, so that the data itself is never modified, and
+// existing rules that only use var relative to the element are unaffected.
+type scopeFrame struct {
+	data   interface{}
+	index  int
+	root   interface{}
+	parent *scopeFrame
+}
+
+// pushScope returns a context for evaluating an inner expression of an
+// array operation called with data. The caller should set the index of the
+// returned frame before evaluating each element.
+func pushScope(ctx context.Context, data interface{}) (context.Context, *scopeFrame) {
+	parent, _ := ctx.Value(scopeFrameKey{}).(*scopeFrame)
+	frame := &scopeFrame{
+		data:   data,
+		root:   data,
+		parent: parent,
+	}
+	if parent != nil {
+		frame.root = parent.root
+	}
+	return context.WithValue(ctx, scopeFrameKey{}, frame), frame
+}
+
+// scopeAt returns the frame the given number of levels up, where 1 is the
+// innermost enclosing array operation.
+func scopeAt(ctx context.Context, levels int) *scopeFrame {
+	frame, _ := ctx.Value(scopeFrameKey{}).(*scopeFrame)
+	for ; frame != nil && levels > 1; levels-- {
+		frame = frame.parent
+	}
+	if levels < 1 {
+		return nil
+	}
+	return frame
+}
+
+// buildLevelsArg builds an optional argument giving a number of scope
+// levels, which defaults to 1.
+func buildLevelsArg(args Arguments, i int, ops OpsSet) (ClauseFunc, error) {
+	if len(args) <= i {
+		return func(context.Context, interface{}) interface{} {
+			return 1.0
+		}, nil
+	}
+	return BuildArgFunc(args[i], ops)
+}
+
+// buildOuterOp builds the outer operation, which looks up a reference, as
+// per var, in the data of an enclosing map, filter, reduce, all, some or
+// none. The optional second argument gives the number of levels to go up,
+// e.g. {"all": [{"var": "items"}, {"<": [{"var": "price"}, {"outer": "budget"}]}]}
+func buildOuterOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	var err error
+	refArg := func(context.Context, interface{}) interface{} {
+		return ""
+	}
+	if len(args) >= 1 {
+		if refArg, err = BuildArgFunc(args[0], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	levelsArg, err := buildLevelsArg(args, 1, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		frame := scopeAt(ctx, int(toNumber(levelsArg(ctx, data))))
+		if frame == nil {
+			return nil
+		}
+		return lookupVar(frame.data, refArg(ctx, data), nil)
+	}, nil
+}
+
+// buildIndexOp builds the index operation, which returns the index of the
+// element currently being evaluated by an enclosing array operation. The
+// optional argument gives the number of levels to go up.
+func buildIndexOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	levelsArg, err := buildLevelsArg(args, 0, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		frame := scopeAt(ctx, int(toNumber(levelsArg(ctx, data))))
+		if frame == nil {
+			return nil
+		}
+		return float64(frame.index)
+	}, nil
+}
+
+// buildRootOp builds the root operation, which looks up a reference, as
+// per var (including the optional default), in the data the rule was
+// originally evaluated against.
+func buildRootOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	var err error
+	refArg := func(context.Context, interface{}) interface{} {
+		return ""
+	}
+	if len(args) >= 1 {
+		if refArg, err = BuildArgFunc(args[0], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	defaultArg := nullf
+	if len(args) >= 2 {
+		if defaultArg, err = BuildArgFunc(args[1], ops); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		root := data
+		if frame, ok := ctx.Value(scopeFrameKey{}).(*scopeFrame); ok {
+			root = frame.root
+		}
+		return lookupVar(root, refArg(ctx, data), defaultArg(ctx, data))
+	}, nil
+}
@@ -0,0 +1,187 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeOps(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   interface{}
+		expect interface{}
+	}
+
+	var data interface{}
+	err := json.Unmarshal([]byte(`{
+		"budget": 25,
+		"items": [{"price": 10}, {"price": 20}],
+		"expensive": [{"price": 10}, {"price": 30}],
+		"groups": [
+			{"limit": 2, "values": [1, 2, 3]},
+			{"limit": 5, "values": [4, 5, 6]}
+		]
+	}`), &data)
+	assert.NoError(t, err)
+
+	tests := []test{
+		{
+			name:   "all-within-budget",
+			rule:   `{"all":[{"var":"items"},{"<":[{"var":"price"},{"outer":"budget"}]}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "all-over-budget",
+			rule:   `{"all":[{"var":"expensive"},{"<":[{"var":"price"},{"outer":"budget"}]}]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "some-over-budget",
+			rule:   `{"some":[{"var":"expensive"},{">":[{"var":"price"},{"outer":"budget"}]}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "none-over-budget",
+			rule:   `{"none":[{"var":"items"},{">":[{"var":"price"},{"outer":"budget"}]}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "filter-outer",
+			rule:   `{"filter":[{"var":"expensive"},{">":[{"var":"price"},{"outer":"budget"}]}]}`,
+			data:   data,
+			expect: []interface{}{map[string]interface{}{"price": float64(30)}},
+		},
+		{
+			name:   "map-index",
+			rule:   `{"map":[{"var":"items"},{"*":[{"var":"price"},{"index":[]}]}]}`,
+			data:   data,
+			expect: []interface{}{float64(0), float64(20)},
+		},
+		{
+			name:   "reduce-outer",
+			rule:   `{"reduce":[{"var":"items"},{"+":[{"var":"accumulator"},{"-":[{"outer":"budget"},{"var":"current.price"}]}]},0]}`,
+			data:   data,
+			expect: float64(20),
+		},
+		{
+			name:   "reduce-index",
+			rule:   `{"reduce":[[5,5,5],{"+":[{"var":"accumulator"},{"index":[]}]},0]}`,
+			expect: float64(3),
+		},
+		{
+			name: "nested-outer",
+			rule: `{"map":[{"var":"groups"},{"filter":[{"var":"values"},{">":[{"var":""},{"outer":"limit"}]}]}]}`,
+			data: data,
+			expect: []interface{}{
+				[]interface{}{float64(3)},
+				[]interface{}{float64(6)},
+			},
+		},
+		{
+			name: "nested-outer-levels",
+			rule: `{"map":[{"var":"groups"},{"map":[{"var":"values"},{"+":[{"var":""},{"outer":["budget",2]}]}]}]}`,
+			data: data,
+			expect: []interface{}{
+				[]interface{}{float64(26), float64(27), float64(28)},
+				[]interface{}{float64(29), float64(30), float64(31)},
+			},
+		},
+		{
+			name: "nested-index",
+			rule: `{"map":[{"var":"groups"},{"map":[{"var":"values"},[{"index":[2]},{"index":[]}]]}]}`,
+			data: data,
+			expect: []interface{}{
+				[]interface{}{
+					[]interface{}{float64(0), float64(0)},
+					[]interface{}{float64(0), float64(1)},
+					[]interface{}{float64(0), float64(2)},
+				},
+				[]interface{}{
+					[]interface{}{float64(1), float64(0)},
+					[]interface{}{float64(1), float64(1)},
+					[]interface{}{float64(1), float64(2)},
+				},
+			},
+		},
+		{
+			name: "nested-root",
+			rule: `{"map":[{"var":"groups"},{"map":[{"var":"values"},{"root":"budget"}]}]}`,
+			data: data,
+			expect: []interface{}{
+				[]interface{}{float64(25), float64(25), float64(25)},
+				[]interface{}{float64(25), float64(25), float64(25)},
+			},
+		},
+		{
+			name:   "root-top-level",
+			rule:   `{"root":"budget"}`,
+			data:   data,
+			expect: float64(25),
+		},
+		{
+			name:   "root-default",
+			rule:   `{"map":[{"var":"items"},{"root":["missing","dflt"]}]}`,
+			data:   data,
+			expect: []interface{}{"dflt", "dflt"},
+		},
+		{
+			name:   "outer-top-level",
+			rule:   `{"outer":"budget"}`,
+			data:   data,
+			expect: nil,
+		},
+		{
+			name:   "outer-too-many-levels",
+			rule:   `{"map":[{"var":"items"},{"outer":["budget",2]}]}`,
+			data:   data,
+			expect: []interface{}{nil, nil},
+		},
+		{
+			name:   "index-top-level",
+			rule:   `{"index":[]}`,
+			expect: nil,
+		},
+		{
+			name:   "var-relative-to-element",
+			rule:   `{"map":[{"var":"items"},{"var":"budget"}]}`,
+			data:   data,
+			expect: []interface{}{nil, nil},
+		},
+		{
+			name:   "find-outer",
+			rule:   `{"find":[{"var":"expensive"},{">":[{"var":"price"},{"outer":"budget"}]}]}`,
+			data:   data,
+			expect: map[string]interface{}{"price": float64(30)},
+		},
+		{
+			name:   "let-and-scope",
+			rule:   `{"let":["b",{"var":"budget"},{"map":[{"var":"items"},{"-":[{"val":"b"},{"outer":"budget"},{"var":"price"}]}]}]}`,
+			data:   data,
+			expect: []interface{}{float64(-10), float64(-20)},
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package jsonlogic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Semantic version operations
+	semverCompareOp   = "semver_compare"
+	semverSatisfiesOp = "semver_satisfies"
+)
+
+// semver is a parsed semantic version, as described at https://semver.org.
+// Build metadata is parsed but ignored for precedence.
+type semver struct {
+	major, minor, patch uint64
+	pre                 []string
+	// parts is the number of components written, so 1 for "2", which is
+	// used by ranges such as ~2.
+	parts int
+}
+
+// parseSemver parses a version string such as "2.10.3-beta.1+build.5".
+// A leading "v" is accepted, and missing minor and patch numbers are
+// treated as zero, so "2" and "2.10" are valid versions.
+func parseSemver(s string) (semver, error) {
+	var v semver
+	str := strings.TrimSpace(s)
+	str = strings.TrimPrefix(str, "v")
+
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		if !validSemverIdents(str[i+1:], false) {
+			return v, fmt.Errorf("invalid build metadata in version %q", s)
+		}
+		str = str[:i]
+	}
+
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		pre := str[i+1:]
+		if !validSemverIdents(pre, true) {
+			return v, fmt.Errorf("invalid pre-release in version %q", s)
+		}
+		v.pre = strings.Split(pre, ".")
+		str = str[:i]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("too many components in version %q", s)
+	}
+
+	nums := [3]uint64{}
+	for i, p := range parts {
+		if !isNumericIdent(p) {
+			return v, fmt.Errorf("invalid number %q in version %q", p, s)
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("invalid number %q in version %q, %w", p, s, err)
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	v.parts = len(parts)
+
+	return v, nil
+}
+
+func isNumericIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	// leading zeros are not permitted
+	return s == "0" || s[0] != '0'
+}
+
+func validSemverIdents(s string, strictNumeric bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		numeric := true
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+		if strictNumeric && numeric && !isNumericIdent(id) {
+			return false
+		}
+	}
+	return true
+}
+
+func compareUint(l, r uint64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compare returns -1, 0 or 1 following semver precedence rules.
+func (v semver) compare(o semver) int {
+	if c := compareUint(v.major, o.major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.minor, o.minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.patch, o.patch); c != 0 {
+		return c
+	}
+
+	// a version without a pre-release has higher precedence
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		l, r := v.pre[i], o.pre[i]
+		ln, lerr := strconv.ParseUint(l, 10, 64)
+		rn, rerr := strconv.ParseUint(r, 10, 64)
+		switch {
+		case lerr == nil && rerr == nil:
+			if c := compareUint(ln, rn); c != 0 {
+				return c
+			}
+		case lerr == nil:
+			// numeric identifiers sort before alphanumeric ones
+			return -1
+		case rerr == nil:
+			return 1
+		default:
+			if c := strings.Compare(l, r); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareUint(uint64(len(v.pre)), uint64(len(o.pre)))
+}
+
+// semverComparator is a single constraint such as ">=1.2.0".
+type semverComparator struct {
+	op      string
+	version semver
+}
+
+func (c semverComparator) matches(v semver) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "=", "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// semverRange is a set of alternatives (separated by "||"), each of which
+// is a set of comparators that must all match.
+type semverRange [][]semverComparator
+
+func (r semverRange) matches(v semver) bool {
+	for _, alt := range r {
+		ok := len(v.pre) == 0
+		for _, c := range alt {
+			if !c.matches(v) {
+				ok = false
+				break
+			}
+			// As with npm, a pre-release only matches alternatives that
+			// name a pre-release of the same version.
+			if len(c.version.pre) > 0 && c.version.major == v.major &&
+				c.version.minor == v.minor && c.version.patch == v.patch {
+				ok = true
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSemverRange parses a range expression. Comparators are separated by
+// whitespace and must all match, alternatives are separated by "||". The
+// supported comparator operators are =, ==, !=, >, >=, <, <=, ~ (patch
+// level changes, or minor level changes if only the major version is
+// given) and ^ (changes that do not modify the left-most non-zero
+// component, or the last component given, if it is zero). A bare version
+// means =. As with npm, ~1 means <2.0.0, and ^0.0 means <0.1.0, and a
+// version with a pre-release, such as 1.2.3-beta, only satisfies an
+// alternative with a comparator on a pre-release of 1.2.3, so it does not
+// satisfy ^1.0.0, but does satisfy >=1.2.3-alpha <1.3.0.
+func parseSemverRange(s string) (semverRange, error) {
+	var r semverRange
+	for _, altStr := range strings.Split(s, "||") {
+		fields := strings.Fields(altStr)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty alternative in range %q", s)
+		}
+
+		var alt []semverComparator
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			op := field[:len(field)-len(strings.TrimLeft(field, "=!<>~^"))]
+			verStr := field[len(op):]
+
+			// allow whitespace between an operator and its version
+			if verStr == "" && i+1 < len(fields) {
+				i++
+				verStr = fields[i]
+			}
+
+			ver, err := parseSemver(verStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q, %w", s, err)
+			}
+
+			switch op {
+			case "", "=", "==", "!=", ">", ">=", "<", "<=":
+				if op == "" {
+					op = "="
+				}
+				alt = append(alt, semverComparator{op: op, version: ver})
+			case "~":
+				upper := semver{major: ver.major, minor: ver.minor + 1}
+				if ver.parts == 1 {
+					upper = semver{major: ver.major + 1}
+				}
+				alt = append(alt,
+					semverComparator{op: ">=", version: ver},
+					semverComparator{op: "<", version: upper},
+				)
+			case "^":
+				var upper semver
+				switch {
+				case ver.major != 0 || ver.parts == 1:
+					upper = semver{major: ver.major + 1}
+				case ver.minor != 0 || ver.parts == 2:
+					upper = semver{minor: ver.minor + 1}
+				default:
+					upper = semver{patch: ver.patch + 1}
+				}
+				alt = append(alt,
+					semverComparator{op: ">=", version: ver},
+					semverComparator{op: "<", version: upper},
+				)
+			default:
+				return nil, fmt.Errorf("invalid operator %q in range %q", op, s)
+			}
+		}
+		r = append(r, alt)
+	}
+	return r, nil
+}
+
+func toSemver(i interface{}) (semver, bool) {
+	str, ok := i.(string)
+	if !ok {
+		return semver{}, false
+	}
+	v, err := parseSemver(str)
+	if err != nil {
+		return semver{}, false
+	}
+	return v, true
+}
+
+func buildSemverCompareOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return nullf, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lVer, ok := toSemver(lArg(ctx, data))
+		if !ok {
+			return nil
+		}
+		rVer, ok := toSemver(rArg(ctx, data))
+		if !ok {
+			return nil
+		}
+		return float64(lVer.compare(rVer))
+	}, nil
+}
+
+func buildSemverSatisfiesOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return falsef, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// A constant range is parsed once, and must be valid.
+	if rval, ok := constantArg(args[1]); ok {
+		rstr, ok := rval.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: range must be a string, got %v", semverSatisfiesOp, rval)
+		}
+		rng, err := parseSemverRange(rstr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", semverSatisfiesOp, err)
+		}
+		return func(ctx context.Context, data interface{}) interface{} {
+			ver, ok := toSemver(lArg(ctx, data))
+			if !ok {
+				return false
+			}
+			return rng.matches(ver)
+		}, nil
+	}
+
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		ver, ok := toSemver(lArg(ctx, data))
+		if !ok {
+			return false
+		}
+		rstr, ok := rArg(ctx, data).(string)
+		if !ok {
+			return false
+		}
+		rng, err := parseSemverRange(rstr)
+		if err != nil {
+			return false
+		}
+		return rng.matches(ver)
+	}, nil
+}
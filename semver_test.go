@@ -0,0 +1,256 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		l, r   string
+		expect int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.10.3", "2.9.12", 1},
+		{"v1.2", "1.2.0", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"2.10.3-beta.1", "2.10.3", -1},
+	}
+
+	for _, st := range tests {
+		t.Run(st.l+" "+st.r, func(t *testing.T) {
+			l, err := parseSemver(st.l)
+			assert.NoError(t, err)
+			r, err := parseSemver(st.r)
+			assert.NoError(t, err)
+			assert.Equal(t, st.expect, l.compare(r))
+			assert.Equal(t, -st.expect, r.compare(l))
+		})
+	}
+}
+
+func TestSemverParseErrors(t *testing.T) {
+	for _, v := range []string{"", "1.2.3.4", "01.2.3", "1.x", "1.0.0-", "1.0.0-01", "1.0.0+", "1.0.0-a..b"} {
+		t.Run(v, func(t *testing.T) {
+			_, err := parseSemver(v)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSemverOps(t *testing.T) {
+	type test struct {
+		name       string
+		rule       string
+		data       interface{}
+		expect     interface{}
+		compileErr string
+	}
+
+	data := map[string]interface{}{
+		"sdk":   "2.10.3-beta.1",
+		"range": ">=2.10.3-alpha <3.0.0",
+		"bad":   "nonsense",
+	}
+
+	tests := []test{
+		{
+			name:   "compare-less",
+			rule:   `{"semver_compare":[{"var":"sdk"},"2.10.3"]}`,
+			data:   data,
+			expect: float64(-1),
+		},
+		{
+			name:   "compare-greater",
+			rule:   `{"semver_compare":[{"var":"sdk"},"2.9.0"]}`,
+			data:   data,
+			expect: float64(1),
+		},
+		{
+			name:   "compare-equal",
+			rule:   `{"semver_compare":["1.2.3","v1.2.3+meta"]}`,
+			expect: float64(0),
+		},
+		{
+			name:   "compare-invalid",
+			rule:   `{"semver_compare":[{"var":"bad"},"1.0.0"]}`,
+			data:   data,
+			expect: nil,
+		},
+		{
+			name:   "compare-one-arg",
+			rule:   `{"semver_compare":["1.0.0"]}`,
+			expect: nil,
+		},
+		{
+			name:   "satisfies-and",
+			rule:   `{"semver_satisfies":[{"var":"sdk"},">=1.2 <2.0"]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "satisfies-or",
+			rule:   `{"semver_satisfies":[{"var":"sdk"},"<2.0 || >=2.10.3-alpha"]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "satisfies-space-after-op",
+			rule:   `{"semver_satisfies":["1.5.0",">= 1.2 < 2.0"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-pre-release-caret",
+			rule:   `{"semver_satisfies":["1.2.3-beta","^1.0.0"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-pre-release-same-version",
+			rule:   `{"semver_satisfies":["1.2.3-beta.2",">=1.2.3-beta.1 <1.3.0"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-pre-release-other-version",
+			rule:   `{"semver_satisfies":["1.2.4-beta",">=1.2.3-beta.1 <1.3.0"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-pre-release-other-alternative",
+			rule:   `{"semver_satisfies":["1.2.3-beta","^1.0.0 || ^1.2.3-alpha"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-release-pre-release-range",
+			rule:   `{"semver_satisfies":["1.2.4",">=1.2.3-beta.1 <1.3.0"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-tilde",
+			rule:   `{"semver_satisfies":["1.2.9","~1.2.3"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-tilde-miss",
+			rule:   `{"semver_satisfies":["1.3.0","~1.2.3"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-tilde-major",
+			rule:   `{"semver_satisfies":["1.9.0","~1"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-tilde-major-miss",
+			rule:   `{"semver_satisfies":["2.0.0","~1"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-tilde-minor",
+			rule:   `{"semver_satisfies":["1.3.0","~1.2"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-caret",
+			rule:   `{"semver_satisfies":["1.9.0","^1.2.3"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-caret-zero",
+			rule:   `{"semver_satisfies":["0.3.0","^0.2.3"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-caret-zero-major",
+			rule:   `{"semver_satisfies":["0.9.0","^0"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-caret-zero-minor",
+			rule:   `{"semver_satisfies":["0.0.9","^0.0"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-caret-zero-minor-miss",
+			rule:   `{"semver_satisfies":["0.1.0","^0.0"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-caret-zero-patch",
+			rule:   `{"semver_satisfies":["0.0.4","^0.0.3"]}`,
+			expect: false,
+		},
+		{
+			name:   "satisfies-bare",
+			rule:   `{"semver_satisfies":["1.2.3","1.2.3"]}`,
+			expect: true,
+		},
+		{
+			name:   "satisfies-dynamic-range",
+			rule:   `{"semver_satisfies":[{"var":"sdk"},{"var":"range"}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "satisfies-dynamic-bad-range",
+			rule:   `{"semver_satisfies":[{"var":"sdk"},{"var":"bad"}]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "satisfies-bad-version",
+			rule:   `{"semver_satisfies":[{"var":"bad"},">=1.0.0"]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:       "satisfies-bad-constant-range",
+			rule:       `{"semver_satisfies":[{"var":"sdk"},">=1.x"]}`,
+			compileErr: `semver_satisfies: invalid range ">=1.x", invalid number "x" in version "1.x"`,
+		},
+		{
+			name:       "satisfies-bad-constant-op",
+			rule:       `{"semver_satisfies":[{"var":"sdk"},"=>1.0"]}`,
+			compileErr: `semver_satisfies: invalid operator "=>" in range "=>1.0"`,
+		},
+		{
+			name:       "satisfies-empty-alternative",
+			rule:       `{"semver_satisfies":[{"var":"sdk"},">=1.0 ||"]}`,
+			compileErr: `semver_satisfies: empty alternative in range ">=1.0 ||"`,
+		},
+		{
+			name:       "satisfies-non-string-range",
+			rule:       `{"semver_satisfies":[{"var":"sdk"},1]}`,
+			compileErr: `semver_satisfies: range must be a string, got 1`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				if st.compileErr != "" {
+					assert.EqualErrorf(t, err, st.compileErr, "compile error")
+					return
+				}
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
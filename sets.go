@@ -0,0 +1,192 @@
+package jsonlogic
+
+import (
+	"context"
+)
+
+const (
+	// Set operations
+	unionOp        = "union"
+	intersectionOp = "intersection"
+	differenceOp   = "difference"
+	uniqueOp       = "unique"
+	containsAllOp  = "contains_all"
+	containsAnyOp  = "contains_any"
+)
+
+// toSlice treats its argument as a set of items. Arrays are used as is, null
+// is the empty set, and any other value is a set of one item (as per merge).
+func toSlice(i interface{}) []interface{} {
+	switch v := i.(type) {
+	case []interface{}:
+		return v
+	case nil:
+		return []interface{}{}
+	default:
+		return []interface{}{v}
+	}
+}
+
+// sliceContains checks for an item using the same equality as in.
+func sliceContains(items []interface{}, item interface{}) bool {
+	for _, i := range items {
+		if IsDeepEqual(item, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends the items not already in resp.
+func appendUnique(resp []interface{}, items ...interface{}) []interface{} {
+	for _, i := range items {
+		if !sliceContains(resp, i) {
+			resp = append(resp, i)
+		}
+	}
+	return resp
+}
+
+func buildUnionOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
+
+	termArgs, err := buildTermArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		resp := []interface{}{}
+		for _, ta := range termArgs {
+			resp = appendUnique(resp, toSlice(ta(ctx, data))...)
+		}
+		return resp
+	}, nil
+}
+
+func buildIntersectionOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
+
+	termArgs, err := buildTermArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		sets := make([][]interface{}, len(termArgs))
+		for i, ta := range termArgs {
+			sets[i] = toSlice(ta(ctx, data))
+		}
+
+		resp := []interface{}{}
+	items:
+		for _, item := range sets[0] {
+			for _, set := range sets[1:] {
+				if !sliceContains(set, item) {
+					continue items
+				}
+			}
+			resp = appendUnique(resp, item)
+		}
+		return resp
+	}, nil
+}
+
+func buildDifferenceOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
+
+	termArgs, err := buildTermArgs(args, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		sets := make([][]interface{}, len(termArgs))
+		for i, ta := range termArgs {
+			sets[i] = toSlice(ta(ctx, data))
+		}
+
+		resp := []interface{}{}
+	items:
+		for _, item := range sets[0] {
+			for _, set := range sets[1:] {
+				if sliceContains(set, item) {
+					continue items
+				}
+			}
+			resp = appendUnique(resp, item)
+		}
+		return resp
+	}, nil
+}
+
+func buildUniqueOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) == 0 {
+		return emptySlice, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		return appendUnique([]interface{}{}, toSlice(lArg(ctx, data))...)
+	}, nil
+}
+
+func buildContainsAllOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return falsef, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice := toSlice(lArg(ctx, data))
+		for _, r := range toSlice(rArg(ctx, data)) {
+			if !sliceContains(lslice, r) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func buildContainsAnyOp(args Arguments, ops OpsSet) (ClauseFunc, error) {
+	if len(args) < 2 {
+		return falsef, nil
+	}
+
+	lArg, err := BuildArgFunc(args[0], ops)
+	if err != nil {
+		return nil, err
+	}
+	rArg, err := BuildArgFunc(args[1], ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, data interface{}) interface{} {
+		lslice := toSlice(lArg(ctx, data))
+		for _, r := range toSlice(rArg(ctx, data)) {
+			if sliceContains(lslice, r) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
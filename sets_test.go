@@ -0,0 +1,162 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOps(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		data   interface{}
+		expect interface{}
+	}
+
+	data := map[string]interface{}{
+		"tags":    []interface{}{"a", "b", "c"},
+		"wanted":  []interface{}{"b", "d"},
+		"numbers": []interface{}{float64(1), float64(2), float64(2), float64(3)},
+	}
+
+	tests := []test{
+		{
+			name:   "union",
+			rule:   `{"union":[{"var":"tags"},{"var":"wanted"}]}`,
+			data:   data,
+			expect: []interface{}{"a", "b", "c", "d"},
+		},
+		{
+			name:   "union-scalars",
+			rule:   `{"union":[1,[2,1],"x",null]}`,
+			expect: []interface{}{float64(1), float64(2), "x"},
+		},
+		{
+			name:   "union-coerced",
+			rule:   `{"union":[[1,2],["1","3"]]}`,
+			expect: []interface{}{float64(1), float64(2), "3"},
+		},
+		{
+			name:   "union-no-args",
+			rule:   `{"union":[]}`,
+			expect: []interface{}{},
+		},
+		{
+			name:   "intersection",
+			rule:   `{"intersection":[{"var":"tags"},{"var":"wanted"}]}`,
+			data:   data,
+			expect: []interface{}{"b"},
+		},
+		{
+			name:   "intersection-many",
+			rule:   `{"intersection":[[1,2,3,4],[2,3,4],[4,3,9]]}`,
+			expect: []interface{}{float64(3), float64(4)},
+		},
+		{
+			name:   "intersection-missing",
+			rule:   `{"intersection":[{"var":"tags"},{"var":"nothing"}]}`,
+			data:   data,
+			expect: []interface{}{},
+		},
+		{
+			name:   "intersection-single",
+			rule:   `{"intersection":[{"var":"numbers"}]}`,
+			data:   data,
+			expect: []interface{}{float64(1), float64(2), float64(3)},
+		},
+		{
+			name:   "difference",
+			rule:   `{"difference":[{"var":"tags"},{"var":"wanted"}]}`,
+			data:   data,
+			expect: []interface{}{"a", "c"},
+		},
+		{
+			name:   "difference-many",
+			rule:   `{"difference":[[1,2,3,4],[2],3]}`,
+			expect: []interface{}{float64(1), float64(4)},
+		},
+		{
+			name:   "unique",
+			rule:   `{"unique":{"var":"numbers"}}`,
+			data:   data,
+			expect: []interface{}{float64(1), float64(2), float64(3)},
+		},
+		{
+			name:   "unique-deep",
+			rule:   `{"unique":[[[1,2],[1,2],[2,1]]]}`,
+			expect: []interface{}{[]interface{}{float64(1), float64(2)}, []interface{}{float64(2), float64(1)}},
+		},
+		{
+			name:   "unique-scalar",
+			rule:   `{"unique":"a"}`,
+			expect: []interface{}{"a"},
+		},
+		{
+			name:   "contains-all",
+			rule:   `{"contains_all":[{"var":"tags"},["a","c"]]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "contains-all-miss",
+			rule:   `{"contains_all":[{"var":"tags"},{"var":"wanted"}]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "contains-all-empty",
+			rule:   `{"contains_all":[{"var":"tags"},[]]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "contains-all-scalar",
+			rule:   `{"contains_all":[{"var":"numbers"},"2"]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "contains-any",
+			rule:   `{"contains_any":[{"var":"tags"},{"var":"wanted"}]}`,
+			data:   data,
+			expect: true,
+		},
+		{
+			name:   "contains-any-miss",
+			rule:   `{"contains_any":[{"var":"tags"},["x","y"]]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "contains-any-missing",
+			rule:   `{"contains_any":[{"var":"nothing"},{"var":"wanted"}]}`,
+			data:   data,
+			expect: false,
+		},
+		{
+			name:   "contains-any-one-arg",
+			rule:   `{"contains_any":[{"var":"tags"}]}`,
+			data:   data,
+			expect: false,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				cf, err := Compile(&c)
+				assert.NoErrorf(t, err, "compile error")
+
+				v := cf(context.Background(), st.data)
+				assert.Equalf(t, st.expect, v, "response data for %v", st.rule)
+			})
+		})
+	}
+}
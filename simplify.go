@@ -0,0 +1,165 @@
+package jsonlogic
+
+// booleanOps are the operations that always return a bool, so !! of them
+// can be removed.
+var booleanOps = map[string]bool{
+	negateOp: true, doubleNegateOp: true,
+	equalOp: true, equalThreeOp: true, notEqualOp: true, notEqualThreeOp: true,
+	lessOp: true, lessEqOp: true, greaterOp: true, greaterEqOp: true,
+}
+
+// Simplify returns a simpler rule that gives the same result as c, for any
+// data, when compiled with DefaultOps. Nested and and or operations are
+// flattened, single argument and and or operations replaced by their
+// argument, double negations removed, literal arguments of and and or
+// short circuited, and if and ?: branches with literal conditions
+// resolved. c is not modified.
+func Simplify(c *Clause) *Clause {
+	if c == nil {
+		return nil
+	}
+	arg := simplifyArg(Argument{Clause: c})
+	if arg.Clause == nil {
+		return literalClause(arg.Value)
+	}
+	return arg.Clause
+}
+
+func literalClause(v interface{}) *Clause {
+	return &Clause{Arguments: Arguments{{Value: v}}}
+}
+
+func literalArg(v interface{}) Argument {
+	return Argument{Clause: literalClause(v)}
+}
+
+// simplifyArg simplifies the arguments of a clause, and then the clause.
+func simplifyArg(arg Argument) Argument {
+	if _, ok := constantArg(arg); ok || arg.Clause == nil {
+		return arg
+	}
+
+	op := arg.Clause.Operator.Name
+	args := make(Arguments, len(arg.Clause.Arguments))
+	for i, a := range arg.Clause.Arguments {
+		args[i] = simplifyArg(a)
+	}
+
+	switch op {
+	case andOp:
+		return simplifyAndOr(args, andOp, false)
+	case orOp:
+		return simplifyAndOr(args, orOp, true)
+	case negateOp, doubleNegateOp:
+		return simplifyNegate(op, args)
+	case ifOp:
+		return simplifyIf(op, args)
+	case ternaryOp:
+		if len(args) <= 3 {
+			return simplifyIf(op, args)
+		}
+	}
+	return opArg(op, args...)
+}
+
+// simplifyAndOr simplifies an and, for which stop is false, or an or, for
+// which stop is true. Both return the first argument whose truthiness is
+// stop, or the last argument.
+func simplifyAndOr(args Arguments, op string, stop bool) Argument {
+	if len(args) == 0 {
+		return literalArg(nil)
+	}
+
+	var flat Arguments
+	for _, a := range args {
+		if a.Clause != nil && a.Clause.Operator.Name == op && len(a.Clause.Arguments) != 0 {
+			flat = append(flat, a.Clause.Arguments...)
+			continue
+		}
+		flat = append(flat, a)
+	}
+
+	var res Arguments
+	for i, a := range flat {
+		v, ok := constantArg(a)
+		if !ok {
+			res = append(res, a)
+			continue
+		}
+		if IsTrue(v) == stop || i == len(flat)-1 {
+			// Later arguments are never evaluated.
+			res = append(res, a)
+			break
+		}
+		// Other literals are skipped over.
+	}
+
+	if _, ok := constantArg(res[0]); ok || len(res) == 1 {
+		return res[0]
+	}
+	return opArg(op, res...)
+}
+
+// simplifyNegate simplifies ! and !!, which only use their first argument.
+func simplifyNegate(op string, args Arguments) Argument {
+	negate := op == negateOp
+	if len(args) == 0 {
+		return literalArg(negate)
+	}
+
+	a := args[0]
+	if v, ok := constantArg(a); ok {
+		return literalArg(IsTrue(v) != negate)
+	}
+	inner := a.Clause.Operator.Name
+	if (inner == negateOp || inner == doubleNegateOp) && len(a.Clause.Arguments) != 0 {
+		// Only the truthiness of the inner argument matters.
+		op = doubleNegateOp
+		if negate != (inner == negateOp) {
+			op = negateOp
+		}
+		return simplifyNegate(op, a.Clause.Arguments[:1])
+	}
+	if !negate && booleanOps[inner] {
+		return a
+	}
+	return opArg(op, a)
+}
+
+// simplifyIf simplifies an if, or a ?: with up to 3 arguments, which
+// behave the same. Conditions are only tested for truthiness, so !! is
+// removed from them.
+func simplifyIf(op string, args Arguments) Argument {
+	var res Arguments
+	taken := false
+	i := 0
+	for ; i+1 < len(args); i += 2 {
+		cond := args[i]
+		if cond.Clause != nil && cond.Clause.Operator.Name == doubleNegateOp && len(cond.Clause.Arguments) != 0 {
+			cond = cond.Clause.Arguments[0]
+		}
+		v, ok := constantArg(cond)
+		if !ok {
+			res = append(res, cond, args[i+1])
+			continue
+		}
+		if IsTrue(v) {
+			// The branch is always taken, so it becomes the else.
+			res = append(res, args[i+1])
+			taken = true
+			break
+		}
+		// The branch is never taken.
+	}
+	if !taken && i+1 == len(args) {
+		res = append(res, args[i])
+	}
+
+	switch len(res) {
+	case 0:
+		return literalArg(nil)
+	case 1:
+		return res[0]
+	}
+	return opArg(op, res...)
+}
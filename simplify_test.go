@@ -0,0 +1,211 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimplify(t *testing.T) {
+	type test struct {
+		name   string
+		rule   string
+		expect string
+	}
+
+	tests := []test{
+		{
+			name:   "flatten",
+			rule:   `{"and": [{"var": "a"}, {"and": [{"var": "b"}, {"and": [{"var": "c"}]}]}, {"or": [{"var": "d"}, {"or": [{"var": "e"}]}]}]}`,
+			expect: `{"and": [{"var": "a"}, {"var": "b"}, {"var": "c"}, {"or": [{"var": "d"}, {"var": "e"}]}]}`,
+		},
+		{
+			name:   "single-argument",
+			rule:   `{"or": [{"and": [{"var": "a"}]}]}`,
+			expect: `{"var": "a"}`,
+		},
+		{
+			name:   "no-arguments",
+			rule:   `{"cat": [{"and": []}, {"or": null}, {"if": []}, {"!": []}, {"!!": []}]}`,
+			expect: `{"cat": [null, null, null, true, false]}`,
+		},
+		{
+			name:   "double-negation",
+			rule:   `{"and": [{"!!": [{"!!": [{"var": "a"}]}]}, {"!": [{"!": [{"var": "b"}]}]}, {"!": {"!!": {"!": [{"var": "c"}]}}}]}`,
+			expect: `{"and": [{"!!": [{"var": "a"}]}, {"!!": [{"var": "b"}]}, {"!!": [{"var": "c"}]}]}`,
+		},
+		{
+			name:   "boolean-double-negation",
+			rule:   `{"!!": [{"<": [{"var": "a"}, 1]}]}`,
+			expect: `{"<": [{"var": "a"}, 1]}`,
+		},
+		{
+			name:   "negated-literal",
+			rule:   `{"or": [{"!": [[]]}, {"var": "a"}]}`,
+			expect: `true`,
+		},
+		{
+			name:   "and-short-circuit",
+			rule:   `{"and": [true, {"var": "a"}, 1, 0, {"var": "b"}]}`,
+			expect: `{"and": [{"var": "a"}, 0]}`,
+		},
+		{
+			name:   "and-last-literal",
+			rule:   `{"and": [{"var": "a"}, "x"]}`,
+			expect: `{"and": [{"var": "a"}, "x"]}`,
+		},
+		{
+			name:   "or-short-circuit",
+			rule:   `{"or": [false, "", {"var": "a"}, null, "yes", {"var": "b"}]}`,
+			expect: `{"or": [{"var": "a"}, "yes"]}`,
+		},
+		{
+			name:   "or-literal-first",
+			rule:   `{"or": [[1], {"var": "a"}]}`,
+			expect: `[1]`,
+		},
+		{
+			name:   "if-true",
+			rule:   `{"if": [true, {"var": "x"}, {"var": "y"}]}`,
+			expect: `{"var": "x"}`,
+		},
+		{
+			name:   "if-false-no-else",
+			rule:   `{"if": [0, {"var": "x"}]}`,
+			expect: `null`,
+		},
+		{
+			name:   "if-dead-branches",
+			rule:   `{"if": [false, 1, {"var": "a"}, 2, "", 3, {"!!": {"var": "b"}}, 4, "yes", 5, {"var": "c"}, 6, 7]}`,
+			expect: `{"if": [{"var": "a"}, 2, {"var": "b"}, 4, 5]}`,
+		},
+		{
+			name:   "ternary",
+			rule:   `{"?:": [{"!!": [{"var": "a"}]}, {"?:": [[], 1, 2]}, 3]}`,
+			expect: `{"?:": [{"var": "a"}, 2, 3]}`,
+		},
+		{
+			name:   "nested-operations",
+			rule:   `{"map": [{"var": "items"}, {"if": [true, {"+": [{"var": ""}, 1]}, 0]}]}`,
+			expect: `{"map": [{"var": "items"}, {"+": [{"var": ""}, 1]}]}`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c, expect Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+				err = json.Unmarshal([]byte(st.expect), &expect)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				before, err := json.Marshal(&c)
+				assert.NoError(t, err)
+				s := Simplify(&c)
+				assertSameRule(t, &expect, s)
+
+				after, err := json.Marshal(&c)
+				assert.NoError(t, err)
+				assert.Equal(t, string(before), string(after), "rule modified")
+			})
+		})
+	}
+}
+
+var (
+	simplifyTestPaths  = []string{"a", "b", "c"}
+	simplifyTestValues = []interface{}{true, false, 0.0, 1.0, "", "x", nil, []interface{}{}, []interface{}{1.0}}
+)
+
+func randomSimplifyValue(r *rand.Rand) interface{} {
+	return simplifyTestValues[r.Intn(len(simplifyTestValues))]
+}
+
+// randomSimplifyRule generates a random rule, mostly from the operations
+// that Simplify rewrites.
+func randomSimplifyRule(r *rand.Rand, depth int) Argument {
+	n := 9
+	if depth <= 0 {
+		n = 3
+	}
+	switch r.Intn(n) {
+	case 0:
+		return Argument{Value: randomSimplifyValue(r)}
+	case 1:
+		return literalArg(randomSimplifyValue(r))
+	case 2:
+		return varArg(simplifyTestPaths[r.Intn(len(simplifyTestPaths))])
+	case 3:
+		ops := []string{equalOp, equalThreeOp, lessOp, greaterEqOp}
+		return opArg(ops[r.Intn(len(ops))], randomSimplifyRule(r, depth-1), randomSimplifyRule(r, depth-1))
+	case 4:
+		ops := []string{negateOp, doubleNegateOp}
+		args := make([]Argument, r.Intn(3))
+		for i := range args {
+			args[i] = randomSimplifyRule(r, depth-1)
+		}
+		return opArg(ops[r.Intn(len(ops))], args...)
+	case 5, 6:
+		ops := []string{ifOp, ternaryOp}
+		args := make([]Argument, r.Intn(7))
+		for i := range args {
+			args[i] = randomSimplifyRule(r, depth-1)
+		}
+		return opArg(ops[r.Intn(len(ops))], args...)
+	default:
+		ops := []string{andOp, orOp}
+		args := make([]Argument, r.Intn(4))
+		for i := range args {
+			args[i] = randomSimplifyRule(r, depth-1)
+		}
+		return opArg(ops[r.Intn(len(ops))], args...)
+	}
+}
+
+func randomSimplifyData(r *rand.Rand) interface{} {
+	data := map[string]interface{}{}
+	for _, p := range simplifyTestPaths {
+		if r.Intn(4) != 0 {
+			data[p] = randomSimplifyValue(r)
+		}
+	}
+	return data
+}
+
+func TestSimplifyRandom(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 5000; i++ {
+		arg := randomSimplifyRule(r, 4)
+		c := arg.Clause
+		if c == nil {
+			c = &Clause{Arguments: Arguments{arg}}
+		}
+		rule, err := json.Marshal(c)
+		assert.NoError(t, err)
+
+		s := Simplify(c)
+		simplified, err := json.Marshal(s)
+		assert.NoError(t, err)
+
+		// Simplifying again changes nothing.
+		assert.Truef(t, s.Equal(Simplify(s)), "resimplifying %s", simplified)
+
+		// The simplified rule gives the same result.
+		cf, err := Compile(c)
+		assert.NoError(t, err)
+		scf, err := Compile(s)
+		if !assert.NoErrorf(t, err, "compiling %s", simplified) {
+			continue
+		}
+		for j := 0; j < 10; j++ {
+			data := randomSimplifyData(r)
+			assert.Equalf(t, cf(ctx, data), scf(ctx, data), "result of %s, simplified to %s, for %v", rule, simplified, data)
+		}
+	}
+}
@@ -0,0 +1,598 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrSQLUnsupported is wrapped by the errors ToSQL returns for rules that
+// can not be expressed in SQL.
+var ErrSQLUnsupported = errors.New("can not be expressed in sql")
+
+// SQLDialect holds the parts of SQL generation that differ between
+// databases. Each function is given SQL expressions, and returns an SQL
+// expression, which must use them once each, in the order given, so that
+// positional placeholders stay in order.
+type SQLDialect struct {
+	// Placeholder returns the placeholder for the n'th parameter,
+	// counting from 1.
+	Placeholder func(n int) string
+	// QuoteIdent quotes a column name.
+	QuoteIdent func(name string) string
+	// Concat concatenates strings.
+	Concat func(exprs []string) string
+	// Divide divides two numbers, without integer division.
+	Divide func(l, r string) string
+	// Contains tests if the string haystack contains needle.
+	Contains func(haystack, needle string) string
+	// IsDistinct tests if two values differ, where NULL is distinct from
+	// every value other than NULL.
+	IsDistinct func(l, r string) string
+}
+
+func quoteIdent(q string) func(name string) string {
+	return func(name string) string {
+		return q + strings.Replace(name, q, q+q, -1) + q
+	}
+}
+
+func questionPlaceholder(int) string {
+	return "?"
+}
+
+func concatOp(exprs []string) string {
+	return "(" + strings.Join(exprs, " || ") + ")"
+}
+
+// PostgresDialect generates SQL for PostgreSQL.
+var PostgresDialect = SQLDialect{
+	Placeholder: func(n int) string {
+		return "$" + strconv.Itoa(n)
+	},
+	QuoteIdent: quoteIdent(`"`),
+	Concat:     concatOp,
+	Divide: func(l, r string) string {
+		return "(CAST(" + l + " AS DOUBLE PRECISION) / " + r + ")"
+	},
+	Contains: func(haystack, needle string) string {
+		return "(strpos(" + haystack + ", " + needle + ") > 0)"
+	},
+	IsDistinct: func(l, r string) string {
+		return "(" + l + " IS DISTINCT FROM " + r + ")"
+	},
+}
+
+// SQLiteDialect generates SQL for SQLite.
+var SQLiteDialect = SQLDialect{
+	Placeholder: questionPlaceholder,
+	QuoteIdent:  quoteIdent(`"`),
+	Concat:      concatOp,
+	Divide: func(l, r string) string {
+		return "(CAST(" + l + " AS REAL) / " + r + ")"
+	},
+	Contains: func(haystack, needle string) string {
+		return "(instr(" + haystack + ", " + needle + ") > 0)"
+	},
+	IsDistinct: func(l, r string) string {
+		return "(" + l + " IS NOT " + r + ")"
+	},
+}
+
+// MySQLDialect generates SQL for MySQL.
+var MySQLDialect = SQLDialect{
+	Placeholder: questionPlaceholder,
+	QuoteIdent:  quoteIdent("`"),
+	Concat: func(exprs []string) string {
+		return "CONCAT(" + strings.Join(exprs, ", ") + ")"
+	},
+	Divide: func(l, r string) string {
+		return "(" + l + " / " + r + ")"
+	},
+	Contains: func(haystack, needle string) string {
+		return "(INSTR(" + haystack + ", " + needle + ") > 0)"
+	},
+	IsDistinct: func(l, r string) string {
+		return "(NOT (" + l + " <=> " + r + "))"
+	},
+}
+
+// SQLOptions configures ToSQL.
+type SQLOptions struct {
+	// Dialect defaults to PostgresDialect.
+	Dialect *SQLDialect
+	// Columns maps var paths, such as "user.age", to SQL expressions, which
+	// are used as is. If Columns is nil, var paths are used as quoted
+	// column names. Otherwise, vars that are not mapped are an error.
+	Columns map[string]string
+}
+
+// ToSQL translates a rule into a parameterised SQL condition, suitable for
+// use in a WHERE clause, and the values of its parameters. The supported
+// operations are var, the logic and comparison operations, in (on a
+// constant array, or a string), missing, missing_some, if, +, -, *, /, %
+// and cat. Errors for any other operation, or for an operation used in a
+// way that can not be expressed, wrap ErrSQLUnsupported.
+//
+// The result follows SQL semantics rather than JavaScript ones. In
+// particular, values are not coerced between types, comparisons with NULL
+// (other than == null and !=) never match, and a var used as a condition is
+// tested with IS TRUE, so should be a boolean column.
+func ToSQL(c *Clause, opts SQLOptions) (string, []interface{}, error) {
+	b := &sqlBuilder{
+		dialect: &PostgresDialect,
+		columns: opts.Columns,
+	}
+	if opts.Dialect != nil {
+		b.dialect = opts.Dialect
+	}
+
+	str, err := b.cond(Argument{Clause: c})
+	if err != nil {
+		return "", nil, err
+	}
+	return str, b.args, nil
+}
+
+type sqlBuilder struct {
+	dialect *SQLDialect
+	columns map[string]string
+	args    []interface{}
+}
+
+func sqlUnsupported(op string) error {
+	return fmt.Errorf("operation %q %w", op, ErrSQLUnsupported)
+}
+
+// param adds a parameter, returning its placeholder. Integral numbers are
+// passed as int64.
+func (b *sqlBuilder) param(v interface{}) string {
+	switch n := v.(type) {
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			v = int64(n)
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			v = i
+		} else if f, err := n.Float64(); err == nil {
+			v = f
+		}
+	}
+	b.args = append(b.args, v)
+	return b.dialect.Placeholder(len(b.args))
+}
+
+// constant renders a constant value, as a parameter where possible.
+func (b *sqlBuilder) constant(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case string:
+		return b.param(v), nil
+	default:
+		if isNumber(v) {
+			return b.param(v), nil
+		}
+		return "", fmt.Errorf("constant %v %w", toString(v), ErrSQLUnsupported)
+	}
+}
+
+// column returns the SQL expression for a var path.
+func (b *sqlBuilder) column(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("var of the whole data %w", ErrSQLUnsupported)
+	}
+	if b.columns == nil {
+		return b.dialect.QuoteIdent(path), nil
+	}
+	col, ok := b.columns[path]
+	if !ok {
+		return "", fmt.Errorf("no sql column is mapped for var %q", path)
+	}
+	return col, nil
+}
+
+// constantPath returns the var path given by a constant argument.
+func constantPath(arg Argument) (string, bool) {
+	v, ok := constantArg(arg)
+	if !ok {
+		return "", false
+	}
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "", true
+	default:
+		if isNumber(v) {
+			return toString(v), true
+		}
+		return "", false
+	}
+}
+
+func (b *sqlBuilder) varExpr(args Arguments) (string, error) {
+	if len(args) == 0 {
+		return b.column("")
+	}
+	path, ok := constantPath(args[0])
+	if !ok {
+		return "", fmt.Errorf("var with a computed path %w", ErrSQLUnsupported)
+	}
+	col, err := b.column(path)
+	if err != nil {
+		return "", err
+	}
+	if len(args) < 2 {
+		return col, nil
+	}
+	def, err := b.value(args[1])
+	if err != nil {
+		return "", err
+	}
+	return "COALESCE(" + col + ", " + def + ")", nil
+}
+
+// values translates each argument as a value.
+func (b *sqlBuilder) values(args Arguments) ([]string, error) {
+	strs := make([]string, len(args))
+	for i, a := range args {
+		str, err := b.value(a)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+// conds translates each argument as a condition.
+func (b *sqlBuilder) conds(args Arguments) ([]string, error) {
+	strs := make([]string, len(args))
+	for i, a := range args {
+		str, err := b.cond(a)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+// value translates an argument to an SQL expression for its value.
+func (b *sqlBuilder) value(arg Argument) (string, error) {
+	if v, ok := constantArg(arg); ok {
+		return b.constant(v)
+	}
+
+	op, args := arg.Clause.Operator.Name, arg.Clause.Arguments
+	switch op {
+	case varOp:
+		return b.varExpr(args)
+	case ifOp, ternaryOp:
+		return b.caseExpr(args, b.value)
+	case plusOp, multiplyOp:
+		if len(args) == 0 {
+			return "", sqlUnsupported(op)
+		}
+		strs, err := b.values(args)
+		if err != nil {
+			return "", err
+		}
+		return "(" + strings.Join(strs, " "+op+" ") + ")", nil
+	case minusOp:
+		strs, err := b.values(args)
+		if err != nil {
+			return "", err
+		}
+		switch len(strs) {
+		case 0:
+			return "", sqlUnsupported(op)
+		case 1:
+			return "(-" + strs[0] + ")", nil
+		default:
+			// Subtraction is left associative, so this folds every
+			// argument, as - does.
+			return "(" + strings.Join(strs, " - ") + ")", nil
+		}
+	case divideOp, moduloOp:
+		if len(args) < 2 {
+			return "", sqlUnsupported(op)
+		}
+		strs, err := b.values(args[:2])
+		if err != nil {
+			return "", err
+		}
+		if op == divideOp {
+			return b.dialect.Divide(strs[0], strs[1]), nil
+		}
+		return "(" + strs[0] + " % " + strs[1] + ")", nil
+	case catOp:
+		if len(args) == 0 {
+			return b.param(""), nil
+		}
+		strs, err := b.values(args)
+		if err != nil {
+			return "", err
+		}
+		return b.dialect.Concat(strs), nil
+	default:
+		// Conditions are boolean values.
+		return b.cond(arg)
+	}
+}
+
+// cond translates an argument to an SQL condition, true when the argument
+// would be truthy.
+func (b *sqlBuilder) cond(arg Argument) (string, error) {
+	if v, ok := constantArg(arg); ok {
+		return b.constant(IsTrue(v))
+	}
+
+	op, args := arg.Clause.Operator.Name, arg.Clause.Arguments
+	switch op {
+	case varOp:
+		col, err := b.varExpr(args)
+		if err != nil {
+			return "", err
+		}
+		return "(" + col + " IS TRUE)", nil
+	case andOp, orOp:
+		if len(args) == 0 {
+			return "FALSE", nil
+		}
+		strs, err := b.conds(args)
+		if err != nil {
+			return "", err
+		}
+		return "(" + strings.Join(strs, " "+strings.ToUpper(op)+" ") + ")", nil
+	case negateOp, doubleNegateOp:
+		if len(args) == 0 {
+			return b.constant(op == negateOp)
+		}
+		str, err := b.cond(args[0])
+		if err != nil {
+			return "", err
+		}
+		if op == negateOp {
+			return "(NOT " + str + ")", nil
+		}
+		return str, nil
+	case ifOp, ternaryOp:
+		return b.caseExpr(args, b.cond)
+	case equalOp, equalThreeOp, notEqualOp, notEqualThreeOp:
+		return b.equality(op, args)
+	case lessOp, lessEqOp, greaterOp, greaterEqOp:
+		return b.comparison(op, args)
+	case inOp:
+		return b.in(args)
+	case missingOp:
+		return b.missing(args)
+	case missingSomeOp:
+		return b.missingSome(args)
+	case plusOp, minusOp, multiplyOp, divideOp, moduloOp, catOp:
+		return "", fmt.Errorf("operation %q as a condition %w", op, ErrSQLUnsupported)
+	default:
+		return "", sqlUnsupported(op)
+	}
+}
+
+// caseExpr translates if, translating the results with f.
+func (b *sqlBuilder) caseExpr(args Arguments, f func(Argument) (string, error)) (string, error) {
+	switch len(args) {
+	case 0:
+		return f(Argument{})
+	case 1:
+		return f(args[0])
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	for i := 0; i+1 < len(args); i += 2 {
+		c, err := b.cond(args[i])
+		if err != nil {
+			return "", err
+		}
+		r, err := f(args[i+1])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" WHEN " + c + " THEN " + r)
+	}
+
+	var last Argument
+	if len(args)%2 == 1 {
+		last = args[len(args)-1]
+	}
+	r, err := f(last)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(" ELSE " + r + " END")
+	return sb.String(), nil
+}
+
+func (b *sqlBuilder) equality(op string, args Arguments) (string, error) {
+	negate := op == notEqualOp || op == notEqualThreeOp
+	if len(args) < 2 {
+		return b.constant(negate != (len(args) == 0))
+	}
+
+	// Comparisons with a null constant are NULL tests.
+	for i, a := range args[:2] {
+		if v, ok := constantArg(a); ok && v == nil {
+			str, err := b.value(args[1-i])
+			if err != nil {
+				return "", err
+			}
+			if negate {
+				return "(" + str + " IS NOT NULL)", nil
+			}
+			return "(" + str + " IS NULL)", nil
+		}
+	}
+
+	strs, err := b.values(args[:2])
+	if err != nil {
+		return "", err
+	}
+	if negate {
+		return b.dialect.IsDistinct(strs[0], strs[1]), nil
+	}
+	return "(" + strs[0] + " = " + strs[1] + ")", nil
+}
+
+func (b *sqlBuilder) comparison(op string, args Arguments) (string, error) {
+	if len(args) < 2 {
+		return "FALSE", nil
+	}
+	if len(args) > 2 && (op == lessOp || op == lessEqOp) {
+		// A between test, the middle value is translated twice so that
+		// the parameters are in order for positional placeholders.
+		strs, err := b.values(Arguments{args[0], args[1], args[1], args[2]})
+		if err != nil {
+			return "", err
+		}
+		return "(" + strs[0] + " " + op + " " + strs[1] + " AND " + strs[2] + " " + op + " " + strs[3] + ")", nil
+	}
+
+	strs, err := b.values(args[:2])
+	if err != nil {
+		return "", err
+	}
+	return "(" + strs[0] + " " + op + " " + strs[1] + ")", nil
+}
+
+func (b *sqlBuilder) in(args Arguments) (string, error) {
+	if len(args) < 2 {
+		return "FALSE", nil
+	}
+
+	if v, ok := constantArg(args[1]); ok {
+		switch v := v.(type) {
+		case []interface{}:
+			if len(v) == 0 {
+				return "FALSE", nil
+			}
+			needle, err := b.value(args[0])
+			if err != nil {
+				return "", err
+			}
+			strs := make([]string, len(v))
+			for i, item := range v {
+				if strs[i], err = b.constant(item); err != nil {
+					return "", err
+				}
+			}
+			return "(" + needle + " IN (" + strings.Join(strs, ", ") + "))", nil
+		case string:
+		default:
+			return "FALSE", nil
+		}
+	}
+
+	// Otherwise, the haystack must be a string. The haystack is translated
+	// first, as it comes first in the SQL.
+	if _, ok := constantArg(args[1]); !ok && args[1].Clause.Operator.Name == nullOp {
+		return "", fmt.Errorf("in on a computed array %w", ErrSQLUnsupported)
+	}
+	haystack, err := b.value(args[1])
+	if err != nil {
+		return "", err
+	}
+	needle, err := b.value(args[0])
+	if err != nil {
+		return "", err
+	}
+	return b.dialect.Contains(haystack, needle), nil
+}
+
+// missingPaths returns the var paths given to missing, if they are all
+// constant.
+func missingPaths(args Arguments) ([]string, bool) {
+	var paths []string
+	for _, a := range args {
+		v, ok := constantArg(a)
+		if !ok {
+			return nil, false
+		}
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			path, ok := constantPath(Argument{Value: item})
+			if !ok {
+				return nil, false
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, true
+}
+
+func (b *sqlBuilder) missing(args Arguments) (string, error) {
+	paths, ok := missingPaths(args)
+	if !ok {
+		return "", fmt.Errorf("missing with computed paths %w", ErrSQLUnsupported)
+	}
+	if len(paths) == 0 {
+		return "FALSE", nil
+	}
+
+	strs := make([]string, len(paths))
+	for i, p := range paths {
+		col, err := b.column(p)
+		if err != nil {
+			return "", err
+		}
+		strs[i] = col + " IS NULL"
+	}
+	return "(" + strings.Join(strs, " OR ") + ")", nil
+}
+
+// missingSome translates missing_some, which is truthy when fewer than
+// the required number of paths are present, and at least one is missing.
+func (b *sqlBuilder) missingSome(args Arguments) (string, error) {
+	if len(args) < 2 {
+		return "FALSE", nil
+	}
+	v, ok := constantArg(args[0])
+	if !ok || !isNumber(v) {
+		return "", fmt.Errorf("missing_some with a computed count %w", ErrSQLUnsupported)
+	}
+	if pv, ok := constantArg(args[1]); ok {
+		if _, ok := pv.([]interface{}); !ok {
+			// Only an array of paths is checked.
+			return "FALSE", nil
+		}
+	}
+	paths, ok := missingPaths(args[1:2])
+	if !ok {
+		return "", fmt.Errorf("missing_some with computed paths %w", ErrSQLUnsupported)
+	}
+	if len(paths) == 0 {
+		return "FALSE", nil
+	}
+
+	present := make([]string, len(paths))
+	missing := make([]string, len(paths))
+	for i, p := range paths {
+		col, err := b.column(p)
+		if err != nil {
+			return "", err
+		}
+		present[i] = "(CASE WHEN " + col + " IS NULL THEN 0 ELSE 1 END)"
+		missing[i] = col + " IS NULL"
+	}
+	return "(((" + strings.Join(present, " + ") + ") < " + b.param(v) + ") AND (" + strings.Join(missing, " OR ") + "))", nil
+}
@@ -0,0 +1,298 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSQL(t *testing.T) {
+	type test struct {
+		name    string
+		rule    string
+		dialect *SQLDialect
+		columns map[string]string
+		expect  string
+		args    []interface{}
+		err     string
+	}
+
+	tests := []test{
+		{
+			name:   "var-condition",
+			rule:   `{"var":"active"}`,
+			expect: `("active" IS TRUE)`,
+		},
+		{
+			name:   "constant-condition",
+			rule:   `true`,
+			expect: `TRUE`,
+		},
+		{
+			name:   "and-comparisons",
+			rule:   `{"and":[{">=":[{"var":"age"},18]},{"==":[{"var":"country"},"GB"]}]}`,
+			expect: `(("age" >= $1) AND ("country" = $2))`,
+			args:   []interface{}{int64(18), "GB"},
+		},
+		{
+			name:    "and-comparisons-sqlite",
+			rule:    `{"and":[{">=":[{"var":"age"},18]},{"==":[{"var":"country"},"GB"]}]}`,
+			dialect: &SQLiteDialect,
+			expect:  `(("age" >= ?) AND ("country" = ?))`,
+			args:    []interface{}{int64(18), "GB"},
+		},
+		{
+			name:    "and-comparisons-mysql",
+			rule:    `{"and":[{">=":[{"var":"age"},18]},{"==":[{"var":"country"},"GB"]}]}`,
+			dialect: &MySQLDialect,
+			expect:  "((`age` >= ?) AND (`country` = ?))",
+			args:    []interface{}{int64(18), "GB"},
+		},
+		{
+			name:   "or-not",
+			rule:   `{"or":[{"!":{"var":"banned"}},{"<":[{"var":"score"},0.5]}]}`,
+			expect: `((NOT ("banned" IS TRUE)) OR ("score" < $1))`,
+			args:   []interface{}{0.5},
+		},
+		{
+			name:   "empty-and",
+			rule:   `{"and":[]}`,
+			expect: `FALSE`,
+		},
+		{
+			name:   "between",
+			rule:   `{"<=":[1,{"var":"n"},10]}`,
+			expect: `($1 <= "n" AND "n" <= $2)`,
+			args:   []interface{}{int64(1), int64(10)},
+		},
+		{
+			name:    "between-repeats-middle-params",
+			rule:    `{"<":[1,{"var":["n",5]},10]}`,
+			dialect: &SQLiteDialect,
+			expect:  `(? < COALESCE("n", ?) AND COALESCE("n", ?) < ?)`,
+			args:    []interface{}{int64(1), int64(5), int64(5), int64(10)},
+		},
+		{
+			name:   "equal-null",
+			rule:   `{"==":[{"var":"email"},null]}`,
+			expect: `("email" IS NULL)`,
+		},
+		{
+			name:   "not-equal-null",
+			rule:   `{"!=":[null,{"var":"email"}]}`,
+			expect: `("email" IS NOT NULL)`,
+		},
+		{
+			name:   "not-equal",
+			rule:   `{"!==":[{"var":"plan"},"free"]}`,
+			expect: `("plan" IS DISTINCT FROM $1)`,
+			args:   []interface{}{"free"},
+		},
+		{
+			name:    "not-equal-sqlite",
+			rule:    `{"!=":[{"var":"plan"},"free"]}`,
+			dialect: &SQLiteDialect,
+			expect:  `("plan" IS NOT ?)`,
+			args:    []interface{}{"free"},
+		},
+		{
+			name:    "not-equal-mysql",
+			rule:    `{"!=":[{"var":"plan"},"free"]}`,
+			dialect: &MySQLDialect,
+			expect:  "(NOT (`plan` <=> ?))",
+			args:    []interface{}{"free"},
+		},
+		{
+			name:   "in-array",
+			rule:   `{"in":[{"var":"country"},["GB","FR"]]}`,
+			expect: `("country" IN ($1, $2))`,
+			args:   []interface{}{"GB", "FR"},
+		},
+		{
+			name:   "in-empty-array",
+			rule:   `{"in":[{"var":"country"},[]]}`,
+			expect: `FALSE`,
+		},
+		{
+			name:   "in-string",
+			rule:   `{"in":["@example.com",{"var":"email"}]}`,
+			expect: `(strpos("email", $1) > 0)`,
+			args:   []interface{}{"@example.com"},
+		},
+		{
+			name:    "in-string-sqlite",
+			rule:    `{"in":[{"var":"code"},"ABCDEF"]}`,
+			dialect: &SQLiteDialect,
+			expect:  `(instr(?, "code") > 0)`,
+			args:    []interface{}{"ABCDEF"},
+		},
+		{
+			name: "in-computed-array",
+			rule: `{"in":[1,[{"var":"a"},{"var":"b"}]]}`,
+			err:  `in on a computed array can not be expressed in sql`,
+		},
+		{
+			name:   "missing",
+			rule:   `{"missing":["a","b"]}`,
+			expect: `("a" IS NULL OR "b" IS NULL)`,
+		},
+		{
+			name:   "not-missing",
+			rule:   `{"!":{"missing":[["a","b"]]}}`,
+			expect: `(NOT ("a" IS NULL OR "b" IS NULL))`,
+		},
+		{
+			name:   "missing-some",
+			rule:   `{"missing_some":[1,["phone","email"]]}`,
+			expect: `((((CASE WHEN "phone" IS NULL THEN 0 ELSE 1 END) + (CASE WHEN "email" IS NULL THEN 0 ELSE 1 END)) < $1) AND ("phone" IS NULL OR "email" IS NULL))`,
+			args:   []interface{}{int64(1)},
+		},
+		{
+			name:    "missing-some-more-than-paths",
+			rule:    `{"missing_some":[3,["age","country"]]}`,
+			dialect: &SQLiteDialect,
+			expect:  `((((CASE WHEN "age" IS NULL THEN 0 ELSE 1 END) + (CASE WHEN "country" IS NULL THEN 0 ELSE 1 END)) < ?) AND ("age" IS NULL OR "country" IS NULL))`,
+			args:    []interface{}{int64(3)},
+		},
+		{
+			name:   "missing-some-no-paths",
+			rule:   `{"missing_some":[1,[]]}`,
+			expect: `FALSE`,
+		},
+		{
+			name:   "missing-some-single-path",
+			rule:   `{"missing_some":[1,"phone"]}`,
+			expect: `FALSE`,
+		},
+		{
+			name:   "arithmetic",
+			rule:   `{">":[{"+":[{"*":[{"var":"price"},{"var":"qty"}]},{"-":[{"var":"shipping"}]}]},{"%":[100,7]}]}`,
+			expect: `((("price" * "qty") + (-"shipping")) > ($1 % $2))`,
+			args:   []interface{}{int64(100), int64(7)},
+		},
+		{
+			name:   "minus-several",
+			rule:   `{"==":[{"-":[{"var":"a"},2,3]},5]}`,
+			expect: `(("a" - $1 - $2) = $3)`,
+			args:   []interface{}{int64(2), int64(3), int64(5)},
+		},
+		{
+			name:    "minus-several-sqlite",
+			rule:    `{"==":[{"-":[{"var":"a"},2,3]},5]}`,
+			dialect: &SQLiteDialect,
+			expect:  `(("a" - ? - ?) = ?)`,
+			args:    []interface{}{int64(2), int64(3), int64(5)},
+		},
+		{
+			name:   "divide",
+			rule:   `{">":[{"/":[{"var":"a"},{"var":"b"}]},1.5]}`,
+			expect: `((CAST("a" AS DOUBLE PRECISION) / "b") > $1)`,
+			args:   []interface{}{1.5},
+		},
+		{
+			name:    "divide-sqlite",
+			rule:    `{">":[{"/":[{"var":"a"},2]},1]}`,
+			dialect: &SQLiteDialect,
+			expect:  `((CAST("a" AS REAL) / ?) > ?)`,
+			args:    []interface{}{int64(2), int64(1)},
+		},
+		{
+			name:   "cat",
+			rule:   `{"==":[{"cat":[{"var":"first"}," ",{"var":"last"}]},"Ada Lovelace"]}`,
+			expect: `(("first" || $1 || "last") = $2)`,
+			args:   []interface{}{" ", "Ada Lovelace"},
+		},
+		{
+			name:    "cat-mysql",
+			rule:    `{"==":[{"cat":[{"var":"first"}," ",{"var":"last"}]},"Ada Lovelace"]}`,
+			dialect: &MySQLDialect,
+			expect:  "(CONCAT(`first`, ?, `last`) = ?)",
+			args:    []interface{}{" ", "Ada Lovelace"},
+		},
+		{
+			name:   "if-condition",
+			rule:   `{"if":[{"var":"vip"},{">":[{"var":"spend"},10]},{">":[{"var":"spend"},100]}]}`,
+			expect: `CASE WHEN ("vip" IS TRUE) THEN ("spend" > $1) ELSE ("spend" > $2) END`,
+			args:   []interface{}{int64(10), int64(100)},
+		},
+		{
+			name:   "if-value",
+			rule:   `{"==":[{"if":[{"var":"vip"},"gold","basic"]},{"var":"tier"}]}`,
+			expect: `(CASE WHEN ("vip" IS TRUE) THEN $1 ELSE $2 END = "tier")`,
+			args:   []interface{}{"gold", "basic"},
+		},
+		{
+			name:    "column-mapping",
+			rule:    `{"and":[{"==":[{"var":"user.country"},"GB"]},{"var":"user.active"}]}`,
+			columns: map[string]string{"user.country": "u.country_code", "user.active": "u.is_active"},
+			expect:  `((u.country_code = $1) AND (u.is_active IS TRUE))`,
+			args:    []interface{}{"GB"},
+		},
+		{
+			name:    "column-not-mapped",
+			rule:    `{"==":[{"var":"user.age"},1]}`,
+			columns: map[string]string{"user.country": "u.country_code"},
+			err:     `no sql column is mapped for var "user.age"`,
+		},
+		{
+			name:   "quoted-column",
+			rule:   `{"var":"we\"ird"}`,
+			expect: `("we""ird" IS TRUE)`,
+		},
+		{
+			name: "unsupported-operation",
+			rule: `{"some":[{"var":"items"},{"var":"x"}]}`,
+			err:  `operation "some" can not be expressed in sql`,
+		},
+		{
+			name: "value-as-condition",
+			rule: `{"and":[{"+":[1,2]}]}`,
+			err:  `operation "+" as a condition can not be expressed in sql`,
+		},
+		{
+			name: "computed-var",
+			rule: `{"var":{"cat":["a","b"]}}`,
+			err:  `var with a computed path can not be expressed in sql`,
+		},
+		{
+			name: "whole-data",
+			rule: `{"==":[{"var":""},1]}`,
+			err:  `var of the whole data can not be expressed in sql`,
+		},
+		{
+			name: "array-constant",
+			rule: `{"==":[{"var":"a"},[1]]}`,
+			err:  `constant 1 can not be expressed in sql`,
+		},
+	}
+
+	for _, st := range tests {
+		t.Run(st.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				var c Clause
+				err := json.Unmarshal([]byte(st.rule), &c)
+				assert.NoErrorf(t, err, "unmarshal error")
+
+				str, args, err := ToSQL(&c, SQLOptions{Dialect: st.dialect, Columns: st.columns})
+				if st.err != "" {
+					assert.EqualError(t, err, st.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, st.expect, str)
+				assert.Equal(t, st.args, args)
+			})
+		})
+	}
+}
+
+func TestToSQLUnsupportedError(t *testing.T) {
+	var c Clause
+	err := json.Unmarshal([]byte(`{"map":[[1],{"var":""}]}`), &c)
+	assert.NoError(t, err)
+
+	_, _, err = ToSQL(&c, SQLOptions{})
+	assert.True(t, errors.Is(err, ErrSQLUnsupported))
+}
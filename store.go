@@ -0,0 +1,265 @@
+package jsonlogic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RuleVersion describes the version of a rule held by a Store.
+type RuleVersion struct {
+	// Version counts the changes to the rule, starting at 1.
+	Version int
+	// SHA256 is the hex encoded hash of the rule file.
+	SHA256   string
+	LoadedAt time.Time
+}
+
+type storeRule struct {
+	RuleVersion
+	cf ClauseFunc
+}
+
+// storeSnapshot is the state of a Store after a reload. It is not modified
+// once stored.
+type storeSnapshot struct {
+	version uint64
+	rules   map[string]storeRule
+	errs    map[string]error
+}
+
+// Store holds the compiled rules from the .json files in a directory, named
+// after the files, without the extension. Call Reload, or Watch, to pick
+// up changes to the files. If a file no longer parses or compiles, the
+// last good version of its rule is kept, and the error is reported by
+// Errors. A Store is safe for concurrent use.
+type Store struct {
+	dir string
+	ops OpsSet
+
+	// mu serialises reloads.
+	mu       sync.Mutex
+	snapshot atomic.Value
+	// failed holds the hashes of the files that failed to load, so they
+	// are not retried until they change.
+	failed map[string]string
+}
+
+// NewStore loads the rules in dir, compiling them with ops, or DefaultOps
+// if ops is nil. Rules that fail to load are reported by Errors, an error
+// is only returned if the directory can not be read.
+func NewStore(dir string, ops OpsSet) (*Store, error) {
+	if ops == nil {
+		ops = DefaultOps
+	}
+	s := &Store{dir: dir, ops: ops, failed: map[string]string{}}
+	s.snapshot.Store(&storeSnapshot{})
+	if err := s.Reload(); err != nil {
+		if _, ok := err.(*StoreError); !ok {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// StoreError is returned by Store.Reload when rule files fail to load.
+type StoreError struct {
+	// Errs holds the error for each rule that failed to load.
+	Errs map[string]error
+}
+
+func (e *StoreError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, name := range sortedErrNames(e.Errs) {
+		msgs = append(msgs, fmt.Sprintf("rule %q, %v", name, e.Errs[name]))
+	}
+	return "could not load " + strings.Join(msgs, ", ")
+}
+
+func sortedErrNames(errs map[string]error) []string {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) load() *storeSnapshot {
+	return s.snapshot.Load().(*storeSnapshot)
+}
+
+// Reload reads the rule files, recompiling those that have changed, and
+// dropping the rules whose files have been removed. A *StoreError is
+// returned if any files fail to load.
+func (s *Store) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("could not read rule directory, %w", err)
+	}
+
+	old := s.load()
+	next := &storeSnapshot{
+		version: old.version,
+		rules:   map[string]storeRule{},
+		errs:    map[string]error{},
+	}
+	// The first load always makes a new version.
+	changed := old.version == 0
+	failed := map[string]string{}
+	for _, e := range entries {
+		// Hidden files include editor backups, and the timestamped
+		// directories of Kubernetes ConfigMap volumes.
+		if strings.HasPrefix(e.Name(), ".") || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ".json")
+		path := filepath.Join(s.dir, e.Name())
+		if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+			// Files may be removed while we read the directory.
+			continue
+		}
+
+		prev, hasPrev := old.rules[name]
+		if hasPrev {
+			next.rules[name] = prev
+		}
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			err = fmt.Errorf("could not read rule, %w", err)
+			if oldErr := old.errs[name]; oldErr != nil && oldErr.Error() == err.Error() {
+				// The same failure as last time, which is not a change.
+				err = oldErr
+			}
+			next.errs[name] = err
+			continue
+		}
+		sum := sha256.Sum256(bs)
+		hash := hex.EncodeToString(sum[:])
+		switch {
+		case hasPrev && prev.SHA256 == hash:
+			continue
+		case s.failed[name] == hash:
+			next.errs[name] = old.errs[name]
+			failed[name] = hash
+			continue
+		}
+
+		cf, err := s.compile(bs)
+		if err != nil {
+			next.errs[name] = err
+			failed[name] = hash
+			continue
+		}
+		next.rules[name] = storeRule{
+			RuleVersion: RuleVersion{
+				Version:  prev.Version + 1,
+				SHA256:   hash,
+				LoadedAt: time.Now(),
+			},
+			cf: cf,
+		}
+		changed = true
+	}
+	changed = changed || len(next.rules) != len(old.rules) || len(next.errs) != len(old.errs)
+	for name, err := range next.errs {
+		changed = changed || err != old.errs[name]
+	}
+
+	s.failed = failed
+	if changed {
+		next.version++
+		s.snapshot.Store(next)
+	}
+	if len(next.errs) != 0 {
+		return &StoreError{Errs: next.errs}
+	}
+	return nil
+}
+
+func (s *Store) compile(bs []byte) (ClauseFunc, error) {
+	var c Clause
+	if err := json.Unmarshal(bs, &c); err != nil {
+		return nil, fmt.Errorf("could not parse rule, %w", err)
+	}
+	cf, err := s.ops.Compile(&c)
+	if err != nil {
+		return nil, fmt.Errorf("could not compile rule, %w", err)
+	}
+	return cf, nil
+}
+
+// Watch calls Reload every interval, until ctx is done, returning the
+// context's error. Errors from Reload are passed to onError, if it is not
+// nil.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Get returns the compiled rule with the given name.
+func (s *Store) Get(name string) (ClauseFunc, bool) {
+	r, ok := s.load().rules[name]
+	return r.cf, ok
+}
+
+// Names returns the names of the rules, in order.
+func (s *Store) Names() []string {
+	rules := s.load().rules
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Version returns the version of the store, which starts at 1, and is
+// incremented by each reload that changes its rules or errors.
+func (s *Store) Version() uint64 {
+	return s.load().version
+}
+
+// Versions returns the version of each rule.
+func (s *Store) Versions() map[string]RuleVersion {
+	rules := s.load().rules
+	vs := make(map[string]RuleVersion, len(rules))
+	for name, r := range rules {
+		vs[name] = r.RuleVersion
+	}
+	return vs
+}
+
+// Errors returns the error for each rule file that failed to load at the
+// last reload. The last good version of those rules, if there is one, is
+// still returned by Get.
+func (s *Store) Errors() map[string]error {
+	errs := s.load().errs
+	res := make(map[string]error, len(errs))
+	for name, err := range errs {
+		res[name] = err
+	}
+	return res
+}
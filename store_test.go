@@ -0,0 +1,181 @@
+package jsonlogic
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeRuleFile(t *testing.T, dir, name, rule string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(rule), 0o644); err != nil {
+		t.Fatalf("could not write rule, %v", err)
+	}
+}
+
+func assertStoreResult(t *testing.T, s *Store, name string, data, expect interface{}) {
+	t.Helper()
+	cf, ok := s.Get(name)
+	if assert.Truef(t, ok, "rule %q missing", name) {
+		assert.Equal(t, expect, cf(context.Background(), data))
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "adult.json", `{">=": [{"var": "age"}, 18]}`)
+	writeRuleFile(t, dir, "uk.json", `{"==": [{"var": "country"}, "GB"]}`)
+	writeRuleFile(t, dir, "notes.txt", `not a rule`)
+	writeRuleFile(t, dir, ".hidden.json", `{"nope": []}`)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "dir.json"), 0o755))
+
+	s, err := NewStore(dir, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"adult", "uk"}, s.Names())
+	assert.Equal(t, uint64(1), s.Version())
+	assert.Empty(t, s.Errors())
+	assertStoreResult(t, s, "adult", map[string]interface{}{"age": 20.0}, true)
+	assert.Equal(t, 1, s.Versions()["adult"].Version)
+	assert.Len(t, s.Versions()["adult"].SHA256, 64)
+
+	// Reloading unchanged files changes nothing.
+	loadedAt := s.Versions()["adult"].LoadedAt
+	assert.NoError(t, s.Reload())
+	assert.Equal(t, uint64(1), s.Version())
+	assert.Equal(t, loadedAt, s.Versions()["adult"].LoadedAt)
+
+	// Changed files are recompiled.
+	writeRuleFile(t, dir, "adult.json", `{">=": [{"var": "age"}, 21]}`)
+	assert.NoError(t, s.Reload())
+	assert.Equal(t, uint64(2), s.Version())
+	assert.Equal(t, 2, s.Versions()["adult"].Version)
+	assert.Equal(t, 1, s.Versions()["uk"].Version)
+	assertStoreResult(t, s, "adult", map[string]interface{}{"age": 20.0}, false)
+
+	// The last good version is kept when a file fails to load.
+	writeRuleFile(t, dir, "adult.json", `{">=": [{"var": "age"}, 21]`)
+	writeRuleFile(t, dir, "new.json", `{"nope": []}`)
+	err = s.Reload()
+	assert.EqualError(t, err, `could not load rule "adult", could not parse rule, unexpected end of JSON input, `+
+		`rule "new", could not compile rule, unrecognized operation nope`)
+	var serr *StoreError
+	assert.True(t, errors.As(err, &serr))
+	assert.Equal(t, uint64(3), s.Version())
+	assert.Equal(t, []string{"adult", "uk"}, s.Names())
+	assert.Len(t, s.Errors(), 2)
+	assertStoreResult(t, s, "adult", map[string]interface{}{"age": 20.0}, false)
+
+	// Failing files are not retried until they change.
+	assert.Error(t, s.Reload())
+	assert.Equal(t, uint64(3), s.Version())
+
+	// Fixing the file clears its error, removing one drops its rule.
+	writeRuleFile(t, dir, "adult.json", `{">=": [{"var": "age"}, 16]}`)
+	assert.NoError(t, os.Remove(filepath.Join(dir, "new.json")))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "uk.json")))
+	assert.NoError(t, s.Reload())
+	assert.Equal(t, uint64(4), s.Version())
+	assert.Equal(t, []string{"adult"}, s.Names())
+	assert.Empty(t, s.Errors())
+	assert.Equal(t, 3, s.Versions()["adult"].Version)
+	assertStoreResult(t, s, "adult", map[string]interface{}{"age": 20.0}, true)
+
+	_, ok := s.Get("uk")
+	assert.False(t, ok)
+}
+
+func TestStoreReadError(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "adult.json", `{">=": [{"var": "age"}, 18]}`)
+	// Reading /proc/self/mem from the start fails, even for root, which
+	// can read files without permission.
+	path := filepath.Join(dir, "unreadable.json")
+	if err := os.Symlink("/proc/self/mem", path); err != nil {
+		t.Skipf("could not link to /proc/self/mem, %v", err)
+	}
+	if _, err := os.ReadFile(path); err == nil {
+		t.Skip("/proc/self/mem is readable")
+	}
+
+	s, err := NewStore(dir, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"adult"}, s.Names())
+	assert.Len(t, s.Errors(), 1)
+	assert.Equal(t, uint64(1), s.Version())
+
+	// A file that still can not be read is not a change.
+	assert.Error(t, s.Reload())
+	assert.Error(t, s.Reload())
+	assert.Equal(t, uint64(1), s.Version())
+
+	// A file that can be read again is.
+	assert.NoError(t, os.Remove(path))
+	writeRuleFile(t, dir, "unreadable.json", `{"var": "a"}`)
+	assert.NoError(t, s.Reload())
+	assert.Equal(t, uint64(2), s.Version())
+	assert.Equal(t, []string{"adult", "unreadable"}, s.Names())
+}
+
+func TestNewStore(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "bad.json", `{`)
+
+	s, err := NewStore(dir, nil)
+	if assert.NoError(t, err) {
+		assert.Empty(t, s.Names())
+		assert.Len(t, s.Errors(), 1)
+		assert.Equal(t, uint64(1), s.Version())
+	}
+
+	_, err = NewStore(filepath.Join(dir, "missing"), nil)
+	assert.Error(t, err)
+}
+
+func TestStoreWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "rule.json", `1`)
+
+	s, err := NewStore(dir, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var errs []error
+	done := make(chan error)
+	go func() {
+		done <- s.Watch(ctx, time.Millisecond, func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		})
+	}()
+
+	writeRuleFile(t, dir, "rule.json", `2`)
+	assert.Eventually(t, func() bool {
+		return s.Version() == 2
+	}, 5*time.Second, time.Millisecond)
+	assertStoreResult(t, s, "rule", nil, 2.0)
+
+	writeRuleFile(t, dir, "rule.json", `{"nope": []}`)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) != 0
+	}, 5*time.Second, time.Millisecond)
+	assertStoreResult(t, s, "rule", nil, 2.0)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
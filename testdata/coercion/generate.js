@@ -0,0 +1,193 @@
+// Generates cases.json, the JavaScript type coercion conformance fixtures
+// used by coercion_test.go. Run with node from this directory:
+//
+//	node generate.js > cases.json
+//
+// The operations below are those of json-logic-js, which delegate all type
+// coercion to the JavaScript runtime.
+"use strict";
+
+const truthy = (v) => (Array.isArray(v) && v.length === 0 ? false : !!v);
+
+const ops = {
+  "<": (a, b, c) => (c === undefined ? a < b : a < b && b < c),
+  "<=": (a, b, c) => (c === undefined ? a <= b : a <= b && b <= c),
+  ">": (a, b) => a > b,
+  ">=": (a, b) => a >= b,
+  "cat": (...args) => args.reduce((a, b) => a + b, ""),
+  "substr": (source, start, end) => {
+    if (end < 0) {
+      const temp = String(source).substr(start);
+      return temp.substr(0, temp.length + end);
+    }
+    return String(source).substr(start, end);
+  },
+  "in": (a, b) => {
+    if (!b || typeof b.indexOf === "undefined") {
+      return false;
+    }
+    return b.indexOf(a) !== -1;
+  },
+};
+
+const fns = {
+  toNumber: (v) => Number(v),
+  toString: (v) => String(v),
+  IsTrue: truthy,
+  IsSoftEqual: (a, b) => a == b,
+};
+
+// Numbers that JSON can not represent are written as {"$number": "..."}.
+function enc(v) {
+  if (typeof v === "number") {
+    if (Number.isNaN(v) || !Number.isFinite(v)) {
+      return { $number: String(v) };
+    }
+    if (Object.is(v, -0)) {
+      return { $number: "-0" };
+    }
+    return v;
+  }
+  if (Array.isArray(v)) {
+    return v.map(enc);
+  }
+  if (v !== null && typeof v === "object") {
+    const o = {};
+    for (const k of Object.keys(v)) {
+      o[k] = enc(v[k]);
+    }
+    return o;
+  }
+  return v;
+}
+
+const isObject = (v) => v !== null && typeof v === "object";
+
+const numbers = [
+  0, -0, 1, -1, 2, 9, 10, 12, 1.5, -1.5, 0.1, 1e21, 1e-7, 123e-20, 1e300,
+  5e-324, 9007199254740992, 123456789012345680000, NaN, Infinity, -Infinity,
+];
+
+const strings = [
+  "", " ", "  12  ", "\t\n 3 \n", "\u00a012\u00a0", "\ufeff7", "\u20281\u2029",
+  "\u00857", "12", "012", "1e3", "1E3", "-1e-7", ".5", "5.", "+5", "-5", "-",
+  "+", ".", "e5", "1e", "1e+", "1e400", "-1e400", "1e-400", "0x1F", "0X1f",
+  "0b101", "0B11", "0o17", "0O7", "-0x10", "+0x10", "0x", "0xg", "0b2", "1_000",
+  "1,000", "Infinity", "-Infinity", "+Infinity", "infinity", "inf", "NaN",
+  "0x1p3", "12abc", "abc", "0", "-0", "00", "false", "true", "null",
+  "undefined", "[object Object]", "1,2", "a", "b", "B", "aa", "10", "9", "2",
+  "héllo", "😀", "\uff01",
+];
+
+const arrays = [
+  [], [[]], [[[]]], [0], [1], [null], [true], [false], ["12"], [" 12 "],
+  [1, 2], [[1]], [[1, 2]], ["a"], [" "], [[], []], [null, null], [1, [2, 3]],
+  [{}], [1.5], [1e21], [-0], [NaN], ["0x10"],
+];
+
+const values = [
+  ...numbers,
+  ...strings,
+  true, false, null,
+  ...arrays,
+  {}, { a: 1, b: 2 },
+];
+
+// A smaller set for operations on pairs and triples of values.
+const pairValues = [
+  0, -0, 1, 2, 10, 1e21, NaN, Infinity, -Infinity,
+  "", "  12  ", "1", "10", "2", "1e3", "0x10", "a", "B", "😀", "\uff01",
+  "[object Object]", true, false, null,
+  [], [[]], [1], [2], ["a"], [1, 2], {},
+];
+
+const betweenValues = [0, 1, 2, "1", "10", "2", null, [1]];
+
+const cases = [];
+
+for (const v of values) {
+  for (const fn of ["toNumber", "toString", "IsTrue"]) {
+    cases.push({ fn, args: [v], expect: fns[fn](v) });
+  }
+  cases.push({ op: "cat", args: [v], expect: ops.cat(v) });
+}
+
+for (const args of [
+  [],
+  ["a", null, "b"],
+  [1, [2, [3, null]], {}],
+  [-0, " ", 1e21, " ", 1e-7],
+  [true, false, [], [[]]],
+]) {
+  cases.push({ op: "cat", args, expect: ops.cat(...args) });
+}
+
+for (const a of pairValues) {
+  for (const b of pairValues) {
+    for (const op of ["<", "<=", ">", ">="]) {
+      cases.push({ op, args: [a, b], expect: ops[op](a, b) });
+    }
+    // Objects compare by identity, which the fixtures can not express.
+    if (!(isObject(a) && isObject(b))) {
+      cases.push({ fn: "IsSoftEqual", args: [a, b], expect: fns.IsSoftEqual(a, b) });
+    }
+  }
+}
+
+for (const a of betweenValues) {
+  for (const b of betweenValues) {
+    for (const c of betweenValues) {
+      for (const op of ["<", "<="]) {
+        cases.push({ op, args: [a, b, c], expect: ops[op](a, b, c) });
+      }
+    }
+  }
+}
+
+const sources = ["jsonlogic", "", "héllo", "a😀b", 12345, null, [1, 2], {}];
+const starts = [undefined, 0, 1, -1, -3, 20, -20, 1.7, -1.7, "2", " 1 ", null, true, "x", [1], NaN, Infinity];
+const lengths = [undefined, 0, 2, -1, -2, 100, -100, null, "3", "-1", 1.5, -1.5, "x", [2], Infinity, -Infinity];
+
+for (const s of sources) {
+  for (const st of starts) {
+    for (const l of lengths) {
+      if (st === undefined && l !== undefined) {
+        continue;
+      }
+      const args = [s];
+      if (st !== undefined) {
+        args.push(st);
+      }
+      if (l !== undefined) {
+        args.push(l);
+      }
+      cases.push({ op: "substr", args, expect: ops.substr(...args) });
+    }
+  }
+}
+
+const needles = ["", "1", "12", "ll", "null", "true", "1,2", "[object Object]", 1, 12, 1.5, 1e21, null, true, [1, 2], {}];
+const haystacks = ["hello", "12", "1.5", "1e+21", "null", "true", "1,2", "[object Object]", ""];
+for (const n of needles) {
+  for (const h of haystacks) {
+    cases.push({ op: "in", args: [n, h], expect: ops.in(n, h) });
+  }
+}
+
+process.stdout.write("[\n");
+process.stdout.write(
+  cases
+    .map((c) => {
+      const out = {};
+      if (c.fn) {
+        out.fn = c.fn;
+      } else {
+        out.op = c.op;
+      }
+      out.args = enc(c.args);
+      out.expect = enc(c.expect);
+      return JSON.stringify(out);
+    })
+    .join(",\n")
+);
+process.stdout.write("\n]\n");